@@ -0,0 +1,39 @@
+package types
+
+import "fmt"
+
+// WithAttachmentMaxFileSize caps the size of any single attachment. Loading
+// an App with an attachment larger than n bytes fails with an
+// AttachmentSizeError naming the offending path.
+func WithAttachmentMaxFileSize(n int64) Option {
+	return func(app *App) error {
+		app.maxAttachmentFileSize = n
+		return nil
+	}
+}
+
+// WithAttachmentMaxTotalSize caps the combined size of all attachments.
+// Loading an App whose attachments sum to more than n bytes fails with an
+// AttachmentSizeError naming the attachment that crossed the limit.
+func WithAttachmentMaxTotalSize(n int64) Option {
+	return func(app *App) error {
+		app.maxAttachmentTotalSize = n
+		return nil
+	}
+}
+
+// AttachmentSizeError is returned when an attachment, or the combined set
+// of attachments, exceeds a configured size limit.
+type AttachmentSizeError struct {
+	Path  string
+	Size  int64
+	Limit int64
+	Total bool
+}
+
+func (e *AttachmentSizeError) Error() string {
+	if e.Total {
+		return fmt.Sprintf("total attachment size %d exceeds limit %d (reached at %s)", e.Size, e.Limit, e.Path)
+	}
+	return fmt.Sprintf("attachment %s has size %d, exceeding limit %d", e.Path, e.Size, e.Limit)
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/docker/app/internal"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+func TestAttachmentMaxFileSizeExceeded(t *testing.T) {
+	dir := fs.NewDir(t, "max-file-size",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile("big.bin", strings.Repeat("a", 1024)),
+	)
+	defer dir.Remove()
+
+	_, err := NewAppFromDefaultFiles(dir.Path(), WithAttachmentMaxFileSize(100))
+	assert.ErrorContains(t, err, "big.bin")
+	sizeErr, ok := err.(*AttachmentSizeError)
+	assert.Assert(t, ok)
+	assert.Equal(t, sizeErr.Path, "big.bin")
+	assert.Equal(t, sizeErr.Limit, int64(100))
+}
+
+func TestAttachmentMaxTotalSizeExceeded(t *testing.T) {
+	dir := fs.NewDir(t, "max-total-size",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile("a.bin", strings.Repeat("a", 100)),
+		fs.WithFile("b.bin", strings.Repeat("b", 100)),
+	)
+	defer dir.Remove()
+
+	_, err := NewAppFromDefaultFiles(dir.Path(), WithAttachmentMaxTotalSize(150))
+	assert.Assert(t, err != nil)
+	sizeErr, ok := err.(*AttachmentSizeError)
+	assert.Assert(t, ok)
+	assert.Assert(t, sizeErr.Total)
+}
+
+func TestAttachmentOpenStreamsContent(t *testing.T) {
+	content := strings.Repeat("x", 5*1024*1024)
+	dir := fs.NewDir(t, "streamed",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile("dataset.bin", content),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+
+	r, err := app.Attachments()[0].Open()
+	assert.NilError(t, err)
+	defer r.Close()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(r)
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Equal(buf.Bytes(), []byte(content)))
+}
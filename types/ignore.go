@@ -0,0 +1,179 @@
+package types
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// appIgnoreFileName is the name of the file used to exclude files from the
+// attachment set, following gitignore/dockerignore conventions.
+const appIgnoreFileName = ".appignore"
+
+// dockerIgnoreFileName is honored as a fallback when no .appignore file is
+// present, so existing Dockerfile-adjacent ignore rules are respected.
+const dockerIgnoreFileName = ".dockerignore"
+
+// ignoreRule is a single parsed line of an ignore file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher evaluates a path against an ordered list of ignore rules,
+// with later rules taking precedence over earlier ones (last match wins).
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// WithAttachmentIgnorePatterns adds extra ignore patterns, evaluated after
+// any patterns loaded from .appignore or .dockerignore, for programmatic
+// use.
+func WithAttachmentIgnorePatterns(patterns ...string) Option {
+	return func(app *App) error {
+		app.extraIgnorePatterns = append(app.extraIgnorePatterns, patterns...)
+		return nil
+	}
+}
+
+func newIgnoreMatcher(dir string, extra []string) (*ignoreMatcher, error) {
+	var lines []string
+
+	loaded, err := readIgnoreFile(filepath.Join(dir, appIgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	if loaded == nil {
+		loaded, err = readIgnoreFile(filepath.Join(dir, dockerIgnoreFileName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	lines = append(lines, loaded...)
+	lines = append(lines, extra...)
+
+	m := &ignoreMatcher{}
+	for _, line := range lines {
+		if rule, ok := parseIgnoreLine(line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+	return m, nil
+}
+
+// readIgnoreFile returns the non-empty lines of the file at path, or nil
+// (with no error) if the file does not exist.
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return readIgnoreLines(f)
+}
+
+func readIgnoreLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	rule.pattern = line
+	return rule, true
+}
+
+// match reports whether the slash-separated relative path rel (a file, or
+// isDir true for a directory) is excluded by the matcher's rules.
+func (m *ignoreMatcher) match(rel string, isDir bool) bool {
+	excluded := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchIgnorePattern(rule.pattern, rel, rule.anchored) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// matchIgnorePattern implements gitignore-style matching: "**" matches any
+// number of path segments (including none), anchored patterns are only
+// matched against the full relative path, and unanchored patterns may also
+// match any suffix of path segments.
+func matchIgnorePattern(pattern, rel string, anchored bool) bool {
+	if matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+		return true
+	}
+	if anchored {
+		return false
+	}
+	segments := strings.Split(rel, "/")
+	for i := 1; i < len(segments); i++ {
+		if matchSegments(strings.Split(pattern, "/"), segments[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return true
+		}
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	ok, err := path.Match(pattern[0], segs[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/docker/app/internal"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+func TestAttachmentsHonorAppIgnore(t *testing.T) {
+	dir := fs.NewDir(t, "appignore",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile(".appignore", "*.log\nbuild/\n!build/keep.txt\n"),
+		fs.WithFile("app.log", "noisy"),
+		fs.WithFile("config.cfg", "something"),
+		fs.WithDir("build",
+			fs.WithFile("artifact.bin", "binary"),
+			fs.WithFile("keep.txt", "kept"),
+		),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+	assert.Equal(t, app.Attachments()[0].Path(), "config.cfg")
+}
+
+// TestAttachmentsNegationReincludesFile exercises "!pattern" re-including
+// a file whose own pattern (not a parent directory) matched first: unlike
+// TestAttachmentsHonorAppIgnore's "!build/keep.txt", where build/ is
+// pruned as a directory before the negation is ever evaluated, important.log
+// here is excluded and re-included by rules that both apply to the file
+// itself.
+func TestAttachmentsNegationReincludesFile(t *testing.T) {
+	dir := fs.NewDir(t, "appignore-negate-file",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile(".appignore", "*.log\n!important.log\n"),
+		fs.WithFile("app.log", "noisy"),
+		fs.WithFile("important.log", "keep this one"),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+	assert.Equal(t, app.Attachments()[0].Path(), "important.log")
+}
+
+func TestAttachmentsIgnoreDirectoryIsPruned(t *testing.T) {
+	dir := fs.NewDir(t, "appignore-prune",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile(".appignore", "vendor/\n"),
+		fs.WithDir("vendor",
+			fs.WithFile("dep.go", "package vendor"),
+			fs.WithDir("nested",
+				fs.WithFile("deep.go", "package nested"),
+			),
+		),
+		fs.WithFile("main.go", "package main"),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+	assert.Equal(t, app.Attachments()[0].Path(), "main.go")
+}
+
+func TestAttachmentsIgnoreFallsBackToDockerIgnore(t *testing.T) {
+	dir := fs.NewDir(t, "dockerignore",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile(".dockerignore", "*.tmp\n"),
+		fs.WithFile("cache.tmp", "scratch"),
+		fs.WithFile("config.cfg", "something"),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+	assert.Equal(t, app.Attachments()[0].Path(), "config.cfg")
+}
+
+func TestAttachmentsIgnoreStillReportsNestedCoreFiles(t *testing.T) {
+	dir := fs.NewDir(t, "appignore-nested-core",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile(".appignore", "*.log\n"),
+		fs.WithDir("nesteddirectory",
+			fs.WithFile(internal.MetadataFileName, validMeta),
+			fs.WithFile(internal.ParametersFileName, `foo: bar`),
+			fs.WithFile(internal.ComposeFileName, validCompose),
+		),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 3))
+}
+
+func TestWithAttachmentIgnorePatterns(t *testing.T) {
+	dir := fs.NewDir(t, "appignore-option",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile("config.cfg", "something"),
+		fs.WithFile("secret.pem", "private"),
+	)
+	defer dir.Remove()
+
+	app, err := NewAppFromDefaultFiles(dir.Path(), WithAttachmentIgnorePatterns("*.pem"))
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(app.Attachments(), 1))
+	assert.Equal(t, app.Attachments()[0].Path(), "config.cfg")
+}
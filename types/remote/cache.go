@@ -0,0 +1,273 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bundleFileName is the name given to the cached artifact within its
+// digest directory.
+const bundleFileName = "bundle"
+
+// metaFileName is the name given to the cache entry's sidecar metadata.
+const metaFileName = "meta.json"
+
+// Cache stores fetched artifacts on disk, keyed by content digest, so
+// repeat loads of the same content can skip the network.
+type Cache interface {
+	// Get returns the cached content for digest, and whether it was
+	// found. A cache entry whose stored content no longer matches its
+	// digest (on-disk corruption) is treated as a miss.
+	Get(digest string) (io.ReadCloser, bool, error)
+	// Put stores r under digest, atomically, and returns the number of
+	// bytes written.
+	Put(digest string, r io.Reader) (int64, error)
+	// Prune removes entries older than maxAge and, if the cache's total
+	// size still exceeds maxTotalSize, evicts least-recently-used entries
+	// until it no longer does. Either limit may be zero to disable it.
+	Prune(maxAge time.Duration, maxTotalSize int64) error
+}
+
+// cacheKey normalizes digest for use as a lookup key: Fetcher
+// implementations return digests in "sha256:<hex>" form, but the
+// algorithm prefix is redundant (this package only ever hashes with
+// sha256) and unsafe to use verbatim in a directory name on every
+// platform, so it's stripped before the digest is used as a cache key or
+// compared against a freshly computed content hash.
+func cacheKey(digest string) string {
+	return strings.TrimPrefix(digest, "sha256:")
+}
+
+type cacheEntryMeta struct {
+	Digest     string    `json:"digest"`
+	Size       int64     `json:"size"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+	AccessedAt time.Time `json:"accessedAt"`
+}
+
+// refIndexer is an optional capability a Cache may implement to remember
+// which digest a reference last resolved to, letting NewAppFromURL skip
+// even the cheap digest-resolution network call (manifest GET, git
+// ls-remote, ...) for a reference it already resolved recently.
+type refIndexer interface {
+	lookupRef(ref string) (digest string, fetchedAt time.Time, ok bool)
+	storeRef(ref, digest string) error
+}
+
+// refIndexFileName is the name of the FileCache's ref-to-digest index,
+// relative to its root directory.
+const refIndexFileName = "refs.json"
+
+// FileCache is the on-disk Cache implementation used by default. Each
+// entry lives at <dir>/<digest>/bundle with a meta.json sidecar recording
+// its size and access times for age/LRU eviction.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache creates a FileCache rooted at dir, creating it if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) entryDir(digest string) string {
+	return filepath.Join(c.dir, cacheKey(digest))
+}
+
+// Get implements Cache.
+func (c *FileCache) Get(digest string) (io.ReadCloser, bool, error) {
+	entryDir := c.entryDir(digest)
+	raw, err := ioutil.ReadFile(filepath.Join(entryDir, bundleFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	sum := sha256.Sum256(raw)
+	if hex.EncodeToString(sum[:]) != cacheKey(digest) {
+		// Corrupted entry: evict it and report a miss so the caller
+		// re-fetches.
+		_ = os.RemoveAll(entryDir)
+		return nil, false, nil
+	}
+
+	meta, err := c.readMeta(digest)
+	if err == nil {
+		meta.AccessedAt = now()
+		_ = c.writeMeta(digest, meta)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(raw)), true, nil
+}
+
+// Put implements Cache.
+func (c *FileCache) Put(digest string, r io.Reader) (int64, error) {
+	entryDir := c.entryDir(digest)
+	if err := os.MkdirAll(entryDir, 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := ioutil.TempFile(entryDir, ".tmp-"+bundleFileName)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(entryDir, bundleFileName)); err != nil {
+		return 0, err
+	}
+
+	meta := cacheEntryMeta{Digest: digest, Size: n, FetchedAt: now(), AccessedAt: now()}
+	if err := c.writeMeta(digest, meta); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Prune implements Cache.
+func (c *FileCache) Prune(maxAge time.Duration, maxTotalSize int64) error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var metas []cacheEntryMeta
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		meta, err := c.readMeta(e.Name())
+		if err != nil {
+			continue
+		}
+		if maxAge > 0 && now().Sub(meta.FetchedAt) > maxAge {
+			if err := os.RemoveAll(c.entryDir(meta.Digest)); err != nil {
+				return err
+			}
+			continue
+		}
+		metas = append(metas, meta)
+	}
+
+	if maxTotalSize <= 0 {
+		return nil
+	}
+	var total int64
+	for _, m := range metas {
+		total += m.Size
+	}
+	if total <= maxTotalSize {
+		return nil
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].AccessedAt.Before(metas[j].AccessedAt)
+	})
+	for _, m := range metas {
+		if total <= maxTotalSize {
+			break
+		}
+		if err := os.RemoveAll(c.entryDir(m.Digest)); err != nil {
+			return err
+		}
+		total -= m.Size
+	}
+	return nil
+}
+
+func (c *FileCache) readMeta(digest string) (cacheEntryMeta, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(c.entryDir(digest), metaFileName))
+	if err != nil {
+		return cacheEntryMeta{}, err
+	}
+	var meta cacheEntryMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return cacheEntryMeta{}, err
+	}
+	return meta, nil
+}
+
+func (c *FileCache) writeMeta(digest string, meta cacheEntryMeta) error {
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(c.entryDir(digest), metaFileName), raw, 0o644)
+}
+
+type refIndexEntry struct {
+	Digest    string    `json:"digest"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+func (c *FileCache) refIndexPath() string {
+	return filepath.Join(c.dir, refIndexFileName)
+}
+
+func (c *FileCache) readRefIndex() (map[string]refIndexEntry, error) {
+	raw, err := ioutil.ReadFile(c.refIndexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]refIndexEntry{}, nil
+		}
+		return nil, err
+	}
+	index := map[string]refIndexEntry{}
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return map[string]refIndexEntry{}, nil
+	}
+	return index, nil
+}
+
+// lookupRef implements refIndexer.
+func (c *FileCache) lookupRef(ref string) (string, time.Time, bool) {
+	index, err := c.readRefIndex()
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	entry, ok := index[ref]
+	return entry.Digest, entry.FetchedAt, ok
+}
+
+// storeRef implements refIndexer.
+func (c *FileCache) storeRef(ref, digest string) error {
+	index, err := c.readRefIndex()
+	if err != nil {
+		return err
+	}
+	index[ref] = refIndexEntry{Digest: digest, FetchedAt: now()}
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.refIndexPath(), raw, 0o644)
+}
+
+// now is a variable so tests can control cache entry timestamps.
+var now = time.Now
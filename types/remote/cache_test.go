@@ -0,0 +1,174 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// testDigest returns the "sha256:..." digest NewFileCache/NewMemoryCache
+// expect to be keyed by bare hex, matching what the fetchers compute.
+func testDigest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFileCachePutGetRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache")
+	assert.NilError(t, err)
+
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	content := "hello world"
+	digest := testDigest(content)
+
+	_, err = cache.Put(digest, strings.NewReader(content))
+	assert.NilError(t, err)
+
+	r, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, hit)
+	defer r.Close()
+
+	raw, err := ioutil.ReadAll(r)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), content)
+}
+
+func TestFileCacheMiss(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache-miss")
+	assert.NilError(t, err)
+
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	_, hit, err := cache.Get(testDigest("never put"))
+	assert.NilError(t, err)
+	assert.Assert(t, !hit)
+}
+
+func TestFileCacheCorruptedEntryIsTransparentlyRefetched(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache-corrupt")
+	assert.NilError(t, err)
+
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	content := "hello world"
+	digest := testDigest(content)
+	_, err = cache.Put(digest, strings.NewReader(content))
+	assert.NilError(t, err)
+
+	// Corrupt the stored bundle so its content no longer matches the
+	// digest naming its directory.
+	err = ioutil.WriteFile(filepath.Join(dir, digest, bundleFileName), []byte("tampered"), 0o644)
+	assert.NilError(t, err)
+
+	_, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, !hit)
+
+	// A subsequent Put transparently repopulates the entry.
+	_, err = cache.Put(digest, strings.NewReader(content))
+	assert.NilError(t, err)
+
+	r, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, hit)
+	raw, err := ioutil.ReadAll(r)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), content)
+}
+
+func TestFileCachePruneByAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache-age")
+	assert.NilError(t, err)
+
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	digest := testDigest("old")
+	_, err = cache.Put(digest, strings.NewReader("old"))
+	assert.NilError(t, err)
+
+	realNow := now
+	now = func() time.Time { return realNow().Add(2 * time.Hour) }
+	defer func() { now = realNow }()
+
+	assert.NilError(t, cache.Prune(time.Hour, 0))
+
+	_, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, !hit)
+}
+
+func TestFileCachePruneByTotalSizeEvictsLRU(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache-lru")
+	assert.NilError(t, err)
+
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	oldDigest := testDigest("aaaaaaaaaa")
+	_, err = cache.Put(oldDigest, strings.NewReader("aaaaaaaaaa"))
+	assert.NilError(t, err)
+
+	newDigest := testDigest("bbbbbbbbbb")
+	_, err = cache.Put(newDigest, strings.NewReader("bbbbbbbbbb"))
+	assert.NilError(t, err)
+
+	// Accessing oldDigest again would normally save it from LRU eviction,
+	// but we prune immediately so insertion order determines it.
+	assert.NilError(t, cache.Prune(0, 15))
+
+	_, oldHit, err := cache.Get(oldDigest)
+	assert.NilError(t, err)
+	assert.Assert(t, !oldHit)
+
+	_, newHit, err := cache.Get(newDigest)
+	assert.NilError(t, err)
+	assert.Assert(t, newHit)
+}
+
+func TestMemoryCacheCorruptedEntryIsTransparentlyRefetched(t *testing.T) {
+	cache := NewMemoryCache()
+	content := "hello"
+	digest := testDigest(content)
+	_, err := cache.Put(digest, strings.NewReader(content))
+	assert.NilError(t, err)
+
+	cache.entries[digest].data = []byte("tampered")
+
+	_, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, !hit)
+
+	_, err = cache.Put(digest, strings.NewReader(content))
+	assert.NilError(t, err)
+
+	r, hit, err := cache.Get(digest)
+	assert.NilError(t, err)
+	assert.Assert(t, hit)
+	raw, err := ioutil.ReadAll(r)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), content)
+}
+
+func TestMemoryCacheRefIndexFreshness(t *testing.T) {
+	cache := NewMemoryCache()
+	assert.NilError(t, cache.storeRef("ref", "digest-1"))
+
+	digest, _, ok := cache.lookupRef("ref")
+	assert.Assert(t, ok)
+	assert.Equal(t, digest, "digest-1")
+
+	_, _, ok = cache.lookupRef("unknown-ref")
+	assert.Assert(t, !ok)
+}
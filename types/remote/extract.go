@@ -0,0 +1,95 @@
+package remote
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// extractTarGz extracts the gzip-compressed tar stream r into destDir.
+func extractTarGz(r io.Reader, destDir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(destDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// safeJoin joins base and name, rejecting names that would escape base
+// (e.g. via "../") to guard against path traversal from a malicious or
+// corrupted archive.
+func safeJoin(base, name string) (string, error) {
+	cleaned := filepath.Clean(string(filepath.Separator) + name)
+	target := filepath.Join(base, cleaned)
+	if !strings.HasPrefix(target, filepath.Clean(base)+string(filepath.Separator)) {
+		return "", fmt.Errorf("remote: archive entry %q escapes destination directory", name)
+	}
+	return target, nil
+}
+
+// walkDirSkippingGit walks dir, invoking fn(path, rel, info) for every
+// entry except the top-level .git directory.
+func walkDirSkippingGit(dir string, fn func(path, rel string, info os.FileInfo) error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == ".git" || strings.HasPrefix(rel, ".git/") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, rel, info)
+	})
+}
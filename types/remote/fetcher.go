@@ -0,0 +1,36 @@
+package remote
+
+import (
+	"context"
+	"io"
+)
+
+// Fetcher retrieves the raw bundle artifact (a tar.gz stream) a Ref
+// points to.
+//
+// Resolution is split in two so that a cache hit can skip the expensive
+// part of the fetch: ResolveDigest identifies the content a Ref currently
+// points to (the digest of the sole layer blob, read from its manifest,
+// for oci://; the hash of the packed tarball for git+ and plain http(s) —
+// neither has a cheaper content identifier than the bytes Fetch would
+// produce) — the same digest Fetch's content is stored under, so a Cache
+// can verify what it holds against it — and Fetch retrieves the content
+// itself, which is only called on a cache miss.
+type Fetcher interface {
+	ResolveDigest(ctx context.Context, ref *Ref) (digest string, err error)
+	Fetch(ctx context.Context, ref *Ref) (content io.ReadCloser, err error)
+}
+
+// fetcherForScheme returns the default Fetcher for scheme.
+func fetcherForScheme(scheme Scheme, client httpDoer) Fetcher {
+	switch scheme {
+	case SchemeHTTP, SchemeHTTPS:
+		return &httpFetcher{client: client}
+	case SchemeOCI:
+		return &ociFetcher{client: client}
+	case SchemeGit:
+		return &gitFetcher{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGitFetcherResolveDigestAndFetch(t *testing.T) {
+	origin := buildGitRepo(t, map[string]string{
+		"metadata.yml": loadTestMeta,
+	})
+	defer os.RemoveAll(origin)
+
+	f := &gitFetcher{}
+	ref, err := ParseRef("git+file://" + origin)
+	assert.NilError(t, err)
+
+	digest, err := f.ResolveDigest(context.Background(), ref)
+	assert.NilError(t, err)
+	assert.Equal(t, digest, "sha256:"+testDigest(string(f.pendingRaw)))
+
+	content, err := f.Fetch(context.Background(), ref)
+	assert.NilError(t, err)
+	defer content.Close()
+	assertGitFetchContains(t, content, "metadata.yml", loadTestMeta)
+}
+
+func TestGitFetcherFetchWithoutPriorResolve(t *testing.T) {
+	origin := buildGitRepo(t, map[string]string{
+		"metadata.yml": loadTestMeta,
+	})
+	defer os.RemoveAll(origin)
+
+	f := &gitFetcher{}
+	ref, err := ParseRef("git+file://" + origin)
+	assert.NilError(t, err)
+
+	content, err := f.Fetch(context.Background(), ref)
+	assert.NilError(t, err)
+	defer content.Close()
+	assertGitFetchContains(t, content, "metadata.yml", loadTestMeta)
+}
+
+func assertGitFetchContains(t *testing.T, content io.Reader, name, expected string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "git-fetch-extract")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NilError(t, extractTarGz(content, dir))
+	raw, err := ioutil.ReadFile(filepath.Join(dir, name))
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), expected)
+}
@@ -0,0 +1,70 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestHTTPFetcherResolveDigestAndFetch(t *testing.T) {
+	const body = "the bundle contents"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	f := &httpFetcher{client: server.Client()}
+	ref, err := ParseRef(server.URL)
+	assert.NilError(t, err)
+
+	digest, err := f.ResolveDigest(context.Background(), ref)
+	assert.NilError(t, err)
+	assert.Equal(t, digest, "sha256:"+testDigest(body))
+
+	content, err := f.Fetch(context.Background(), ref)
+	assert.NilError(t, err)
+	defer content.Close()
+
+	raw := make([]byte, len(body))
+	_, err = content.Read(raw)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), body)
+}
+
+func TestHTTPFetcherFetchWithoutPriorResolve(t *testing.T) {
+	const body = "fetched without resolving first"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	f := &httpFetcher{client: server.Client()}
+	ref, err := ParseRef(server.URL)
+	assert.NilError(t, err)
+
+	content, err := f.Fetch(context.Background(), ref)
+	assert.NilError(t, err)
+	defer content.Close()
+
+	raw := make([]byte, len(body))
+	_, err = content.Read(raw)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), body)
+}
+
+func TestHTTPFetcherUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	f := &httpFetcher{client: server.Client()}
+	ref, err := ParseRef(server.URL)
+	assert.NilError(t, err)
+
+	_, err = f.ResolveDigest(context.Background(), ref)
+	assert.ErrorContains(t, err, "unexpected status")
+}
@@ -0,0 +1,175 @@
+package remote
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/app/types"
+)
+
+// config holds the options accumulated from Option values passed to
+// NewAppFromURL.
+type config struct {
+	cacheDir    string
+	cacheMaxAge time.Duration
+	cache       Cache
+	fetchers    map[Scheme]Fetcher
+	httpClient  httpDoer
+	appOptions  []types.Option
+}
+
+// Option configures NewAppFromURL.
+type Option func(*config)
+
+// WithCacheDir sets the directory the on-disk cache is rooted at. If
+// unset, a subdirectory of the user's cache directory is used.
+func WithCacheDir(path string) Option {
+	return func(c *config) { c.cacheDir = path }
+}
+
+// WithCacheMaxAge sets how long a cached artifact is trusted before it is
+// re-fetched. Zero means cached artifacts are always considered fresh
+// once present.
+func WithCacheMaxAge(d time.Duration) Option {
+	return func(c *config) { c.cacheMaxAge = d }
+}
+
+// WithCache overrides the cache implementation entirely, e.g. with an
+// in-memory Cache in tests.
+func WithCache(cache Cache) Option {
+	return func(c *config) { c.cache = cache }
+}
+
+// WithFetcher overrides the Fetcher used for scheme, e.g. with an
+// oras-style fake registry client in tests.
+func WithFetcher(scheme Scheme, fetcher Fetcher) Option {
+	return func(c *config) {
+		if c.fetchers == nil {
+			c.fetchers = map[Scheme]Fetcher{}
+		}
+		c.fetchers[scheme] = fetcher
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used by the http(s) and oci
+// fetchers, e.g. with an httptest.Server's client in tests.
+func WithHTTPClient(client httpDoer) Option {
+	return func(c *config) { c.httpClient = client }
+}
+
+// WithAppOptions passes additional options through to the underlying
+// types.NewAppFromDefaultFiles call once the bundle has been fetched and
+// extracted.
+func WithAppOptions(opts ...types.Option) Option {
+	return func(c *config) { c.appOptions = append(c.appOptions, opts...) }
+}
+
+// resolveDigest returns the digest ref currently points to. If cache
+// implements refIndexer and already has a fresh (within maxAge) record of
+// what ref resolved to, that digest is reused without calling the
+// fetcher at all; otherwise the fetcher resolves it and, if possible, the
+// result is recorded for next time.
+func resolveDigest(ctx context.Context, cache Cache, fetcher Fetcher, ref *Ref, maxAge time.Duration) (string, error) {
+	indexer, ok := cache.(refIndexer)
+	if ok && maxAge > 0 {
+		if digest, fetchedAt, found := indexer.lookupRef(ref.Raw); found && time.Since(fetchedAt) <= maxAge {
+			return digest, nil
+		}
+	}
+
+	digest, err := fetcher.ResolveDigest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		_ = indexer.storeRef(ref.Raw, digest)
+	}
+	return digest, nil
+}
+
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "docker-app", "remote-cache")
+}
+
+// NewAppFromURL fetches the app bundle ref points to (an https:// tarball,
+// an oci:// image, or a git+https:// repository) and loads it the same
+// way NewAppFromDefaultFiles does, using a local content-addressed cache
+// to skip the network on repeat loads.
+func NewAppFromURL(ctx context.Context, ref string, opts ...Option) (*types.App, error) {
+	parsed, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	cache := cfg.cache
+	if cache == nil {
+		cacheDir := cfg.cacheDir
+		if cacheDir == "" {
+			cacheDir = defaultCacheDir()
+		}
+		cache, err = NewFileCache(cacheDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fetcher := cfg.fetchers[parsed.Scheme]
+	if fetcher == nil {
+		fetcher = fetcherForScheme(parsed.Scheme, cfg.httpClient)
+	}
+
+	digest, err := resolveDigest(ctx, cache, fetcher, parsed, cfg.cacheMaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	content, hit, err := cache.Get(digest)
+	if err != nil {
+		return nil, err
+	}
+	if !hit {
+		fetched, err := fetcher.Fetch(ctx, parsed)
+		if err != nil {
+			return nil, err
+		}
+		defer fetched.Close()
+		if _, err := cache.Put(digest, fetched); err != nil {
+			return nil, err
+		}
+		content, _, err = cache.Get(digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer content.Close()
+
+	dir, err := ioutil.TempDir("", "docker-app-remote")
+	if err != nil {
+		return nil, err
+	}
+	if err := extractTarGz(content, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	appOptions := append([]types.Option{types.WithCleanup(func() { os.RemoveAll(dir) })}, cfg.appOptions...)
+	app, err := types.NewAppFromDefaultFiles(dir, appOptions...)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,301 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+const (
+	loadTestMeta = `name: test-app
+version: 0.1.0`
+	loadTestCompose = `version: "3.0"
+services:
+  web:
+    image: nginx`
+)
+
+func buildBundle(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	files := map[string]string{
+		"metadata.yml":       loadTestMeta,
+		"docker-compose.yml": loadTestCompose,
+		"parameters.yml":     "foo: bar",
+	}
+	for name, content := range files {
+		assert.NilError(t, tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o644,
+			Size: int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tw.Close())
+	assert.NilError(t, gw.Close())
+	return buf.Bytes()
+}
+
+// countingFetcher wraps a Fetcher, counting calls so tests can assert the
+// cache spared the network on repeat loads.
+type countingFetcher struct {
+	Fetcher
+	resolveCalls int
+	fetchCalls   int
+}
+
+func (f *countingFetcher) ResolveDigest(ctx context.Context, ref *Ref) (string, error) {
+	f.resolveCalls++
+	return f.Fetcher.ResolveDigest(ctx, ref)
+}
+
+func (f *countingFetcher) Fetch(ctx context.Context, ref *Ref) (io.ReadCloser, error) {
+	f.fetchCalls++
+	return f.Fetcher.Fetch(ctx, ref)
+}
+
+type staticFetcher struct {
+	digest string
+	bundle []byte
+}
+
+func (f *staticFetcher) ResolveDigest(ctx context.Context, ref *Ref) (string, error) {
+	return f.digest, nil
+}
+
+func (f *staticFetcher) Fetch(ctx context.Context, ref *Ref) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(f.bundle)), nil
+}
+
+func TestNewAppFromURLOCIFake(t *testing.T) {
+	bundle := buildBundle(t)
+	digest := testDigest(string(bundle))
+	fetcher := &countingFetcher{Fetcher: &staticFetcher{digest: digest, bundle: bundle}}
+
+	cache := NewMemoryCache()
+	app, err := NewAppFromURL(context.Background(), "oci://fake-registry.example/myorg/myapp:1.0",
+		WithCache(cache),
+		WithFetcher(SchemeOCI, fetcher),
+	)
+	assert.NilError(t, err)
+	defer app.Cleanup()
+
+	assertContentIs(t, app.MetadataRaw(), loadTestMeta)
+	assert.Equal(t, fetcher.fetchCalls, 1)
+
+	// A second load of the same ref hits the cache and never calls Fetch
+	// again.
+	app2, err := NewAppFromURL(context.Background(), "oci://fake-registry.example/myorg/myapp:1.0",
+		WithCache(cache),
+		WithFetcher(SchemeOCI, fetcher),
+	)
+	assert.NilError(t, err)
+	defer app2.Cleanup()
+
+	assert.Equal(t, fetcher.fetchCalls, 1)
+	assert.Equal(t, fetcher.resolveCalls, 2)
+}
+
+func TestNewAppFromURLSkipsResolveWithFreshRefIndex(t *testing.T) {
+	bundle := buildBundle(t)
+	digest := testDigest(string(bundle))
+	fetcher := &countingFetcher{Fetcher: &staticFetcher{digest: digest, bundle: bundle}}
+
+	cache := NewMemoryCache()
+	ref := "oci://fake-registry.example/myorg/myapp:1.0"
+
+	app, err := NewAppFromURL(context.Background(), ref,
+		WithCache(cache),
+		WithFetcher(SchemeOCI, fetcher),
+		WithCacheMaxAge(0),
+	)
+	assert.NilError(t, err)
+	app.Cleanup()
+	assert.Equal(t, fetcher.resolveCalls, 1)
+
+	// With a fresh ref-index record and a non-zero max age, the second
+	// load skips even the cheap ResolveDigest call.
+	app2, err := NewAppFromURL(context.Background(), ref,
+		WithCache(cache),
+		WithFetcher(SchemeOCI, fetcher),
+		WithCacheMaxAge(time.Hour),
+	)
+	assert.NilError(t, err)
+	app2.Cleanup()
+
+	assert.Equal(t, fetcher.resolveCalls, 1)
+	assert.Equal(t, fetcher.fetchCalls, 1)
+}
+
+func TestNewAppFromURLCorruptedCacheEntryIsRefetched(t *testing.T) {
+	bundle := buildBundle(t)
+	digest := testDigest(string(bundle))
+	fetcher := &countingFetcher{Fetcher: &staticFetcher{digest: digest, bundle: bundle}}
+
+	cache := NewMemoryCache()
+	ref := "oci://fake-registry.example/myorg/myapp:1.0"
+
+	app, err := NewAppFromURL(context.Background(), ref, WithCache(cache), WithFetcher(SchemeOCI, fetcher))
+	assert.NilError(t, err)
+	app.Cleanup()
+	assert.Equal(t, fetcher.fetchCalls, 1)
+
+	// Corrupt the cached bundle directly.
+	cache.entries[digest].data = []byte("tampered")
+
+	app2, err := NewAppFromURL(context.Background(), ref, WithCache(cache), WithFetcher(SchemeOCI, fetcher))
+	assert.NilError(t, err)
+	defer app2.Cleanup()
+
+	assertContentIs(t, app2.MetadataRaw(), loadTestMeta)
+	assert.Equal(t, fetcher.fetchCalls, 2)
+}
+
+func assertContentIs(t *testing.T, data []byte, expected string) {
+	t.Helper()
+	assert.Equal(t, string(data), expected)
+}
+
+// TestNewAppFromURLHTTPSRealFetcherAndCache goes through NewAppFromURL
+// end to end with the real httpFetcher and a real FileCache, rather than
+// the staticFetcher/countingFetcher doubles the other tests in this file
+// use: it's what caught the digest-format mismatch between httpFetcher's
+// "sha256:"-prefixed digests and the cache's own content hash.
+func TestNewAppFromURLHTTPSRealFetcherAndCache(t *testing.T) {
+	bundle := buildBundle(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bundle)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "filecache-e2e")
+	assert.NilError(t, err)
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	app, err := NewAppFromURL(context.Background(), server.URL,
+		WithCache(cache),
+		WithHTTPClient(server.Client()),
+	)
+	assert.NilError(t, err)
+	defer app.Cleanup()
+	assertContentIs(t, app.MetadataRaw(), loadTestMeta)
+
+	// A second load must hit the cache rather than re-fetching.
+	app2, err := NewAppFromURL(context.Background(), server.URL,
+		WithCache(cache),
+		WithHTTPClient(server.Client()),
+	)
+	assert.NilError(t, err)
+	defer app2.Cleanup()
+	assertContentIs(t, app2.MetadataRaw(), loadTestMeta)
+}
+
+// TestNewAppFromURLOCIRealFetcherAndCache is the OCI counterpart of
+// TestNewAppFromURLHTTPSRealFetcherAndCache: the real ociFetcher against
+// the fake registry from oci_fetcher_test.go, and a real MemoryCache.
+func TestNewAppFromURLOCIRealFetcherAndCache(t *testing.T) {
+	const registryHost = "fake-registry.example"
+	bundle := buildBundle(t)
+	registry := newFakeRegistry("myorg/myapp", "1.0", bundle)
+
+	server := httptest.NewServer(registry.handler())
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	client := &rewriteHostTransport{registryHost: registryHost, addr: addr}
+
+	cache := NewMemoryCache()
+	ref := fmt.Sprintf("oci://%s/myorg/myapp:1.0", registryHost)
+
+	app, err := NewAppFromURL(context.Background(), ref,
+		WithCache(cache),
+		WithHTTPClient(client),
+	)
+	assert.NilError(t, err)
+	defer app.Cleanup()
+	assertContentIs(t, app.MetadataRaw(), loadTestMeta)
+
+	// The cached entry is keyed by the blob's own digest, so it's found
+	// directly by content hash, not just by whatever ResolveDigest
+	// happened to return last time.
+	_, hit, err := cache.Get(registry.blobDigest())
+	assert.NilError(t, err)
+	assert.Assert(t, hit)
+}
+
+// buildGitRepo creates a local git repository containing files, committed
+// on its default branch, and returns its path.
+func buildGitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "app-git-origin")
+	assert.NilError(t, err)
+
+	runGitCmd(t, dir, "init", "--quiet")
+	runGitCmd(t, dir, "config", "user.email", "test@example.com")
+	runGitCmd(t, dir, "config", "user.name", "test")
+	for name, content := range files {
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	runGitCmd(t, dir, "add", "-A")
+	runGitCmd(t, dir, "commit", "--quiet", "-m", "initial")
+	return dir
+}
+
+func runGitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NilError(t, err, string(out))
+}
+
+// TestNewAppFromURLGitRealFetcherAndCache is the git+ counterpart of
+// TestNewAppFromURLHTTPSRealFetcherAndCache: the real gitFetcher, cloning
+// a local repository, against a real FileCache. It's what caught
+// gitFetcher.ResolveDigest returning a commit SHA that FileCache's
+// content-hash check could never match.
+func TestNewAppFromURLGitRealFetcherAndCache(t *testing.T) {
+	origin := buildGitRepo(t, map[string]string{
+		"metadata.yml":       loadTestMeta,
+		"docker-compose.yml": loadTestCompose,
+		"parameters.yml":     "foo: bar",
+	})
+	defer os.RemoveAll(origin)
+
+	dir, err := ioutil.TempDir("", "filecache-e2e-git")
+	assert.NilError(t, err)
+	cache, err := NewFileCache(dir)
+	assert.NilError(t, err)
+
+	ref := "git+file://" + origin
+
+	app, err := NewAppFromURL(context.Background(), ref, WithCache(cache))
+	assert.NilError(t, err)
+	defer app.Cleanup()
+	assertContentIs(t, app.MetadataRaw(), loadTestMeta)
+
+	// A second load must hit the cache rather than re-cloning.
+	app2, err := NewAppFromURL(context.Background(), ref, WithCache(cache))
+	assert.NilError(t, err)
+	defer app2.Cleanup()
+	assertContentIs(t, app2.MetadataRaw(), loadTestMeta)
+}
@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-memory Cache implementation, useful as a
+// substitute for FileCache in tests.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*memoryCacheEntry
+	refs    map[string]refIndexEntry
+}
+
+type memoryCacheEntry struct {
+	data       []byte
+	fetchedAt  time.Time
+	accessedAt time.Time
+}
+
+// NewMemoryCache creates an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]*memoryCacheEntry{}, refs: map[string]refIndexEntry{}}
+}
+
+// lookupRef implements refIndexer.
+func (c *MemoryCache) lookupRef(ref string) (string, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.refs[ref]
+	return entry.Digest, entry.FetchedAt, ok
+}
+
+// storeRef implements refIndexer.
+func (c *MemoryCache) storeRef(ref, digest string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refs[ref] = refIndexEntry{Digest: digest, FetchedAt: now()}
+	return nil
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(digest string) (io.ReadCloser, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey(digest)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	sum := sha256.Sum256(entry.data)
+	if hex.EncodeToString(sum[:]) != key {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	entry.accessedAt = now()
+	return ioutil.NopCloser(bytes.NewReader(entry.data)), true, nil
+}
+
+// Put implements Cache.
+func (c *MemoryCache) Put(digest string, r io.Reader) (int64, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(digest)] = &memoryCacheEntry{data: data, fetchedAt: now(), accessedAt: now()}
+	return int64(len(data)), nil
+}
+
+// Prune implements Cache.
+func (c *MemoryCache) Prune(maxAge time.Duration, maxTotalSize int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxAge > 0 {
+		for digest, entry := range c.entries {
+			if now().Sub(entry.fetchedAt) > maxAge {
+				delete(c.entries, digest)
+			}
+		}
+	}
+
+	if maxTotalSize <= 0 {
+		return nil
+	}
+	var total int64
+	digests := make([]string, 0, len(c.entries))
+	for digest, entry := range c.entries {
+		total += int64(len(entry.data))
+		digests = append(digests, digest)
+	}
+	if total <= maxTotalSize {
+		return nil
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return c.entries[digests[i]].accessedAt.Before(c.entries[digests[j]].accessedAt)
+	})
+	for _, digest := range digests {
+		if total <= maxTotalSize {
+			break
+		}
+		total -= int64(len(c.entries[digest].data))
+		delete(c.entries, digest)
+	}
+	return nil
+}
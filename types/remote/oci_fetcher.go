@@ -0,0 +1,147 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ociManifestMediaType is the media type requested when resolving a tag
+// to a manifest.
+const ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+// ociManifest is the minimal subset of an OCI image manifest this fetcher
+// needs: a single layer carrying the app bundle as a tar.gz blob.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociFetcher fetches a bundle packaged as the sole layer of an OCI image,
+// using the plain HTTP(S) distribution-spec API (no external registry
+// client dependency).
+type ociFetcher struct {
+	client httpDoer
+}
+
+// ResolveDigest implements Fetcher: a single manifest GET, cheap compared
+// to downloading the (potentially large) layer blob. The returned digest
+// is the blob's own digest as declared by the manifest (the same digest
+// Fetch will store the blob under), not a hash of the manifest itself,
+// so it matches the content the cache actually holds.
+func (f *ociFetcher) ResolveDigest(ctx context.Context, ref *Ref) (string, error) {
+	manifestRaw, _, err := f.getManifest(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	layer, err := singleLayer(manifestRaw, ref)
+	if err != nil {
+		return "", err
+	}
+	return layer.Digest, nil
+}
+
+// Fetch implements Fetcher: resolves the manifest again (cheap) and
+// downloads its sole layer blob.
+func (f *ociFetcher) Fetch(ctx context.Context, ref *Ref) (io.ReadCloser, error) {
+	manifestRaw, location, err := f.getManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := singleLayer(manifestRaw, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	blobURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", location.registry, location.repository, layer.Digest)
+	blobRaw, err := f.get(ctx, blobURL, layer.MediaType)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(blobRaw)), nil
+}
+
+// singleLayer parses manifestRaw and returns its sole layer descriptor,
+// rejecting manifests that don't carry exactly one.
+func singleLayer(manifestRaw []byte, ref *Ref) (ociDescriptor, error) {
+	var manifest ociManifest
+	if err := json.Unmarshal(manifestRaw, &manifest); err != nil {
+		return ociDescriptor{}, fmt.Errorf("remote: parsing OCI manifest for %s: %s", ref.Raw, err)
+	}
+	if len(manifest.Layers) != 1 {
+		return ociDescriptor{}, fmt.Errorf("remote: expected exactly one layer in OCI manifest for %s, got %d", ref.Raw, len(manifest.Layers))
+	}
+	return manifest.Layers[0], nil
+}
+
+type ociLocation struct {
+	registry   string
+	repository string
+	tag        string
+}
+
+func (f *ociFetcher) getManifest(ctx context.Context, ref *Ref) ([]byte, ociLocation, error) {
+	registry, repository, tag, err := splitOCILocation(ref.Location)
+	if err != nil {
+		return nil, ociLocation{}, err
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, tag)
+	raw, err := f.get(ctx, manifestURL, ociManifestMediaType)
+	if err != nil {
+		return nil, ociLocation{}, err
+	}
+	return raw, ociLocation{registry: registry, repository: repository, tag: tag}, nil
+}
+
+func (f *ociFetcher) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote: GET %s: unexpected status %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// splitOCILocation splits "registry/repository:tag" into its parts,
+// defaulting to tag "latest" when omitted.
+func splitOCILocation(location string) (registry, repository, tag string, err error) {
+	slash := strings.Index(location, "/")
+	if slash == -1 {
+		return "", "", "", fmt.Errorf("remote: malformed oci reference %q", location)
+	}
+	registry = location[:slash]
+	rest := location[slash+1:]
+
+	tag = "latest"
+	if idx := strings.LastIndex(rest, ":"); idx != -1 {
+		repository, tag = rest[:idx], rest[idx+1:]
+	} else {
+		repository = rest
+	}
+	if repository == "" {
+		return "", "", "", fmt.Errorf("remote: malformed oci reference %q", location)
+	}
+	return registry, repository, tag, nil
+}
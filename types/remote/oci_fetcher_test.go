@@ -0,0 +1,137 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// fakeRegistry is a minimal, in-memory stand-in for an OCI distribution-spec
+// registry, in the style of the fake registries ORAS's own tests use: it
+// serves exactly the manifest/blob GETs ociFetcher issues, nothing more.
+type fakeRegistry struct {
+	repository string
+	tag        string
+	blob       []byte
+}
+
+func newFakeRegistry(repository, tag string, blob []byte) *fakeRegistry {
+	return &fakeRegistry{repository: repository, tag: tag, blob: blob}
+}
+
+func (r *fakeRegistry) blobDigest() string {
+	sum := sha256.Sum256(r.blob)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (r *fakeRegistry) manifest() []byte {
+	manifest := ociManifest{Layers: []ociDescriptor{{
+		MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+		Digest:    r.blobDigest(),
+		Size:      int64(len(r.blob)),
+	}}}
+	raw, _ := json.Marshal(manifest)
+	return raw
+}
+
+func (r *fakeRegistry) handler() http.HandlerFunc {
+	manifestPath := fmt.Sprintf("/v2/%s/manifests/%s", r.repository, r.tag)
+	blobPath := fmt.Sprintf("/v2/%s/blobs/%s", r.repository, r.blobDigest())
+	return func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case manifestPath:
+			w.Write(r.manifest())
+		case blobPath:
+			w.Write(r.blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// rewriteHostTransport rewrites requests for registryHost to target addr
+// instead, so an ociFetcher built against a fixed "registry/repo:tag"
+// location can be pointed at an httptest.Server.
+type rewriteHostTransport struct {
+	registryHost string
+	addr         string
+}
+
+func (t *rewriteHostTransport) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == t.registryHost {
+		req.URL.Host = t.addr
+		req.URL.Scheme = "http"
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func TestOCIFetcherResolveDigestAndFetch(t *testing.T) {
+	const registryHost = "fake-registry.example"
+	registry := newFakeRegistry("myorg/myapp", "1.0", []byte("the oci bundle contents"))
+
+	server := httptest.NewServer(registry.handler())
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	client := &rewriteHostTransport{registryHost: registryHost, addr: addr}
+
+	f := &ociFetcher{client: client}
+	ref, err := ParseRef(fmt.Sprintf("oci://%s/myorg/myapp:1.0", registryHost))
+	assert.NilError(t, err)
+
+	digest, err := f.ResolveDigest(context.Background(), ref)
+	assert.NilError(t, err)
+	assert.Equal(t, digest, registry.blobDigest())
+
+	content, err := f.Fetch(context.Background(), ref)
+	assert.NilError(t, err)
+	defer content.Close()
+
+	raw := make([]byte, len(registry.blob))
+	_, err = content.Read(raw)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), string(registry.blob))
+}
+
+func TestOCIFetcherDefaultsToLatestTag(t *testing.T) {
+	ref, err := ParseRef("oci://fake-registry.example/myorg/myapp")
+	assert.NilError(t, err)
+
+	registry, repository, tag, err := splitOCILocation(ref.Location)
+	assert.NilError(t, err)
+	assert.Equal(t, registry, "fake-registry.example")
+	assert.Equal(t, repository, "myorg/myapp")
+	assert.Equal(t, tag, "latest")
+}
+
+func TestOCIFetcherRejectsMultiLayerManifest(t *testing.T) {
+	manifest := ociManifest{Layers: []ociDescriptor{
+		{Digest: "sha256:a"},
+		{Digest: "sha256:b"},
+	}}
+	raw, err := json.Marshal(manifest)
+	assert.NilError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write(raw)
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	client := &rewriteHostTransport{registryHost: "fake-registry.example", addr: addr}
+
+	f := &ociFetcher{client: client}
+	ref, err := ParseRef("oci://fake-registry.example/myorg/myapp:1.0")
+	assert.NilError(t, err)
+
+	_, err = f.Fetch(context.Background(), ref)
+	assert.ErrorContains(t, err, "expected exactly one layer")
+}
@@ -0,0 +1,58 @@
+// Package remote loads App bundles from remote sources (plain HTTPS
+// tarballs, OCI registries, and git repositories), backed by a local
+// content-addressed cache so repeat loads skip the network.
+package remote
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scheme identifies which fetcher handles a Ref.
+type Scheme string
+
+// Supported schemes.
+const (
+	SchemeHTTP  Scheme = "http"
+	SchemeHTTPS Scheme = "https"
+	SchemeOCI   Scheme = "oci"
+	SchemeGit   Scheme = "git"
+)
+
+// Ref is a parsed remote application reference, one of:
+//   - https://host/path/to/app.tgz
+//   - oci://registry/repository:tag
+//   - git+https://host/repo.git#ref
+type Ref struct {
+	Scheme Scheme
+	// Location is the scheme-specific remainder of the reference: the
+	// full URL for http(s), "registry/repository:tag" for oci, and the
+	// repository URL (without the git+ prefix or #ref fragment) for git.
+	Location string
+	// GitRef is the branch, tag, or commit to use for git references.
+	// Empty selects the repository's default branch.
+	GitRef string
+	// Raw is the original, unparsed reference string.
+	Raw string
+}
+
+// ParseRef parses a remote application reference.
+func ParseRef(ref string) (*Ref, error) {
+	switch {
+	case strings.HasPrefix(ref, "https://"):
+		return &Ref{Scheme: SchemeHTTPS, Location: ref, Raw: ref}, nil
+	case strings.HasPrefix(ref, "http://"):
+		return &Ref{Scheme: SchemeHTTP, Location: ref, Raw: ref}, nil
+	case strings.HasPrefix(ref, "oci://"):
+		return &Ref{Scheme: SchemeOCI, Location: strings.TrimPrefix(ref, "oci://"), Raw: ref}, nil
+	case strings.HasPrefix(ref, "git+"):
+		rest := strings.TrimPrefix(ref, "git+")
+		location, gitRef := rest, ""
+		if idx := strings.LastIndex(rest, "#"); idx != -1 {
+			location, gitRef = rest[:idx], rest[idx+1:]
+		}
+		return &Ref{Scheme: SchemeGit, Location: location, GitRef: gitRef, Raw: ref}, nil
+	default:
+		return nil, fmt.Errorf("remote: unsupported reference %q (expected https://, oci:// or git+https:// scheme)", ref)
+	}
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/app/internal"
+	"github.com/docker/app/types/tuf"
+)
+
+// WithSignatureVerification enables TUF-style signature verification for
+// the App. trustedRoot is the caller's trusted root of trust: a signed
+// root.json document, self-verified against its own listed root keys and
+// threshold (a standard TOFU bootstrap). Verify must be called explicitly
+// after the App is loaded to actually check the bundle.
+func WithSignatureVerification(trustedRoot io.Reader) Option {
+	return func(app *App) error {
+		raw, err := ioutil.ReadAll(trustedRoot)
+		if err != nil {
+			return err
+		}
+		var signed tuf.Signed
+		if err := json.Unmarshal(raw, &signed); err != nil {
+			return fmt.Errorf("failed to parse trusted root: %s", err)
+		}
+		var bootstrap tuf.RootSigned
+		if err := json.Unmarshal(signed.Signed, &bootstrap); err != nil {
+			return fmt.Errorf("failed to parse trusted root: %s", err)
+		}
+		root, err := tuf.VerifyRoot(&bootstrap, &signed)
+		if err != nil {
+			return fmt.Errorf("failed to verify trusted root: %s", err)
+		}
+		app.trustedRoot = root
+		return nil
+	}
+}
+
+// SignableFiles returns the bundle-relative paths a packaging pipeline
+// should pass to tuf.NewTargets when signing a: its core files, any
+// ignore file present (.appignore/.dockerignore, so it too ends up
+// tracked rather than flagged as an unlisted extra), and every
+// attachment — the same, already-ignore-filtered and size-limited set
+// Attachments() reports, not a raw walk of a.Path.
+func (a *App) SignableFiles() []string {
+	paths := []string{internal.MetadataFileName, internal.ComposeFileName, internal.ParametersFileName}
+	for _, name := range []string{appIgnoreFileName, dockerIgnoreFileName} {
+		if _, err := os.Stat(filepath.Join(a.Path, name)); err == nil {
+			paths = append(paths, name)
+		}
+	}
+	for _, attachment := range a.Attachments() {
+		paths = append(paths, attachment.Path())
+	}
+	return paths
+}
+
+// Verify checks the App's signatures/ directory against the App's
+// trusted root (set via WithSignatureVerification): the root, timestamp,
+// snapshot and targets roles are verified in turn, then every file under
+// the App directory is checked against the hashes recorded in
+// targets.json.
+func (a *App) Verify() error {
+	if a.trustedRoot == nil {
+		return fmt.Errorf("signature verification was not configured for this app")
+	}
+
+	sigDir := filepath.Join(a.Path, tuf.SignaturesDir)
+
+	rootSigned, err := readSigned(filepath.Join(sigDir, tuf.RootFileName))
+	if err != nil {
+		return err
+	}
+	root, err := tuf.VerifyRoot(a.trustedRoot, rootSigned)
+	if err != nil {
+		return err
+	}
+
+	timestampSigned, err := readSigned(filepath.Join(sigDir, tuf.TimestampFileName))
+	if err != nil {
+		return err
+	}
+	timestamp, err := tuf.VerifyTimestamp(root, timestampSigned)
+	if err != nil {
+		return err
+	}
+
+	snapshotSigned, err := readSigned(filepath.Join(sigDir, tuf.SnapshotFileName))
+	if err != nil {
+		return err
+	}
+	snapshot, err := tuf.VerifySnapshot(root, timestamp, snapshotSigned)
+	if err != nil {
+		return err
+	}
+
+	targetsSigned, err := readSigned(filepath.Join(sigDir, tuf.TargetsFileName))
+	if err != nil {
+		return err
+	}
+	targets, err := tuf.VerifyTargets(root, snapshot, targetsSigned)
+	if err != nil {
+		return err
+	}
+
+	return tuf.VerifyDir(targets, a.Path)
+}
+
+func readSigned(path string) (*tuf.Signed, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var signed tuf.Signed
+	if err := json.Unmarshal(raw, &signed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+	return &signed, nil
+}
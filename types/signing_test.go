@@ -0,0 +1,170 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/app/internal"
+	"github.com/docker/app/types/tuf"
+
+	"gotest.tools/assert"
+	"gotest.tools/fs"
+)
+
+func sha256And512(raw []byte) (map[string]string, int64) {
+	sum256 := sha256.Sum256(raw)
+	sum512 := sha512.Sum512(raw)
+	return map[string]string{
+		"sha256": hex.EncodeToString(sum256[:]),
+		"sha512": hex.EncodeToString(sum512[:]),
+	}, int64(len(raw))
+}
+
+func signApp(t *testing.T, dir string) *bytes.Buffer {
+	t.Helper()
+	expires := time.Now().Add(24 * time.Hour)
+
+	rootKey, err := tuf.NewEd25519Key()
+	assert.NilError(t, err)
+	targetsKey, err := tuf.NewEd25519Key()
+	assert.NilError(t, err)
+	snapshotKey, err := tuf.NewEd25519Key()
+	assert.NilError(t, err)
+	timestampKey, err := tuf.NewEd25519Key()
+	assert.NilError(t, err)
+
+	root := tuf.NewRoot(1, expires, map[string]int{
+		tuf.RoleRoot: 1, tuf.RoleTargets: 1, tuf.RoleSnapshot: 1, tuf.RoleTimestamp: 1,
+	}, map[string][]*tuf.PrivateKey{
+		tuf.RoleRoot:      {rootKey},
+		tuf.RoleTargets:   {targetsKey},
+		tuf.RoleSnapshot:  {snapshotKey},
+		tuf.RoleTimestamp: {timestampKey},
+	})
+	rootSigned, err := tuf.SignDocument(root, rootKey)
+	assert.NilError(t, err)
+
+	app, err := NewAppFromDefaultFiles(dir)
+	assert.NilError(t, err)
+
+	targets, err := tuf.NewTargets(dir, app.SignableFiles(), 1, expires)
+	assert.NilError(t, err)
+	targetsSigned, err := tuf.SignDocument(targets, targetsKey)
+	assert.NilError(t, err)
+
+	snapshot := buildSnapshot(t, targetsSigned, expires)
+	snapshotSigned, err := tuf.SignDocument(snapshot, snapshotKey)
+	assert.NilError(t, err)
+
+	timestamp := buildTimestamp(t, snapshotSigned, expires)
+	timestampSigned, err := tuf.SignDocument(timestamp, timestampKey)
+	assert.NilError(t, err)
+
+	sigDir := filepath.Join(dir, tuf.SignaturesDir)
+	assert.NilError(t, os.MkdirAll(sigDir, 0o755))
+	writeJSON(t, filepath.Join(sigDir, tuf.RootFileName), rootSigned)
+	writeJSON(t, filepath.Join(sigDir, tuf.TargetsFileName), targetsSigned)
+	writeJSON(t, filepath.Join(sigDir, tuf.SnapshotFileName), snapshotSigned)
+	writeJSON(t, filepath.Join(sigDir, tuf.TimestampFileName), timestampSigned)
+
+	var buf bytes.Buffer
+	assert.NilError(t, json.NewEncoder(&buf).Encode(rootSigned))
+	return &buf
+}
+
+// These helpers hash the already-signed child document's canonical bytes
+// without needing access to tuf's unexported hashing helper.
+func buildSnapshot(t *testing.T, targetsSigned *tuf.Signed, expires time.Time) *tuf.SnapshotSigned {
+	t.Helper()
+	hashes, length := sha256And512(targetsSigned.Signed)
+	return &tuf.SnapshotSigned{
+		Type: tuf.RoleSnapshot, Version: 1, Expires: expires,
+		Meta: map[string]tuf.MetaFileMeta{
+			tuf.TargetsFileName: {Version: 1, Length: length, Hashes: hashes},
+		},
+	}
+}
+
+func buildTimestamp(t *testing.T, snapshotSigned *tuf.Signed, expires time.Time) *tuf.TimestampSigned {
+	t.Helper()
+	hashes, length := sha256And512(snapshotSigned.Signed)
+	return &tuf.TimestampSigned{
+		Type: tuf.RoleTimestamp, Version: 1, Expires: expires,
+		Meta: map[string]tuf.MetaFileMeta{
+			tuf.SnapshotFileName: {Version: 1, Length: length, Hashes: hashes},
+		},
+	}
+}
+
+func writeJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(path, raw, 0o644))
+}
+
+func TestAppVerifySignedBundle(t *testing.T) {
+	dir := fs.NewDir(t, "verify-bundle",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+	)
+	defer dir.Remove()
+
+	trustedRoot := signApp(t, dir.Path())
+
+	app, err := NewAppFromDefaultFiles(dir.Path(), WithSignatureVerification(trustedRoot))
+	assert.NilError(t, err)
+	assert.NilError(t, app.Verify())
+}
+
+func TestAppVerifyDetectsTampering(t *testing.T) {
+	dir := fs.NewDir(t, "verify-tampered",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+	)
+	defer dir.Remove()
+
+	trustedRoot := signApp(t, dir.Path())
+
+	app, err := NewAppFromDefaultFiles(dir.Path(), WithSignatureVerification(trustedRoot))
+	assert.NilError(t, err)
+
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir.Path(), internal.ComposeFileName), []byte("tampered"), 0o644))
+
+	err = app.Verify()
+	assert.Assert(t, err != nil)
+}
+
+func TestAppVerifyHonorsAppIgnoreAtSigningTime(t *testing.T) {
+	dir := fs.NewDir(t, "verify-appignore",
+		fs.WithFile(internal.MetadataFileName, validMeta),
+		fs.WithFile(internal.ParametersFileName, `foo: bar`),
+		fs.WithFile(internal.ComposeFileName, validCompose),
+		fs.WithFile("keep.txt", "keep me"),
+		fs.WithFile("build.log", "drop me"),
+		fs.WithFile(appIgnoreFileName, "*.log\n"),
+	)
+	defer dir.Remove()
+
+	trustedRoot := signApp(t, dir.Path())
+
+	// A packaging pipeline strips .appignore-matched files before
+	// distributing the bundle; targets.json must not have listed
+	// build.log in the first place, or this would fail with a
+	// "missing from bundle" FileIntegrityError.
+	assert.NilError(t, os.Remove(filepath.Join(dir.Path(), "build.log")))
+
+	app, err := NewAppFromDefaultFiles(dir.Path(), WithSignatureVerification(trustedRoot))
+	assert.NilError(t, err)
+	assert.NilError(t, app.Verify())
+}
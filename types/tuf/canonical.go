@@ -0,0 +1,83 @@
+package tuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// canonicalJSON deterministically re-encodes v: object keys are sorted,
+// and no insignificant whitespace is emitted. This guarantees that
+// signing the same logical document always produces the same bytes, so
+// signatures are reproducible and verifiable across implementations.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, generic); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			kb, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(kb)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, value[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case string, bool, nil:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case json.Number:
+		// Decoded with UseNumber, so large integers (e.g. a multi-petabyte
+		// length) pass through as their original digits instead of being
+		// round-tripped through float64, which would silently change their
+		// value before signing.
+		buf.WriteString(value.String())
+	default:
+		return fmt.Errorf("canonicalJSON: unsupported type %T", v)
+	}
+	return nil
+}
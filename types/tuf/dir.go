@@ -0,0 +1,86 @@
+package tuf
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NewTargets builds a TargetsSigned with an entry — length and hashes —
+// for each of paths, read relative to dir. The caller decides which
+// paths belong in the bundle (for example types.App's core files plus
+// its already-ignore-filtered Attachments()); NewTargets itself does not
+// walk dir, so files a packaging pipeline excludes before distribution
+// (build artifacts, an .appignore-matched path, ...) are never recorded
+// in the first place.
+func NewTargets(dir string, paths []string, version int, expires time.Time) (*TargetsSigned, error) {
+	targets := &TargetsSigned{
+		Type:    RoleTargets,
+		Version: version,
+		Expires: expires,
+		Targets: map[string]TargetFileMeta{},
+	}
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return nil, err
+		}
+		hashes, length := hashFile(raw)
+		targets.Targets[filepath.ToSlash(path)] = TargetFileMeta{Length: length, Hashes: hashes}
+	}
+	return targets, nil
+}
+
+// VerifyDir walks dir (excluding the signatures directory itself) and
+// checks every file against targets: every file on disk must have a
+// matching, hash-correct entry, and every entry in targets must exist on
+// disk. Either a missing file, an unlisted extra file, or a hash mismatch
+// fails with a FileIntegrityError naming the offending path.
+func VerifyDir(targets *TargetsSigned, dir string) error {
+	seen := map[string]bool{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == SignaturesDir || hasSignaturesPrefix(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		raw, readErr := ioutil.ReadFile(path)
+		if readErr != nil {
+			return readErr
+		}
+		if verifyErr := VerifyFile(targets, rel, raw); verifyErr != nil {
+			return verifyErr
+		}
+		seen[rel] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for path := range targets.Targets {
+		if !seen[path] {
+			return &FileIntegrityError{Path: path, Reason: "missing from bundle"}
+		}
+	}
+	return nil
+}
+
+func hasSignaturesPrefix(rel string) bool {
+	return rel == SignaturesDir || len(rel) > len(SignaturesDir) && rel[:len(SignaturesDir)+1] == SignaturesDir+"/"
+}
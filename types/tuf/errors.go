@@ -0,0 +1,52 @@
+package tuf
+
+import (
+	"fmt"
+	"time"
+)
+
+// ThresholdError is returned when fewer than the required number of valid
+// signatures are present for a role.
+type ThresholdError struct {
+	Role      string
+	Valid     int
+	Threshold int
+}
+
+func (e *ThresholdError) Error() string {
+	return fmt.Sprintf("tuf: role %s has %d valid signature(s), threshold requires %d", e.Role, e.Valid, e.Threshold)
+}
+
+// ExpiredError is returned when a role's metadata has passed its
+// expiration date.
+type ExpiredError struct {
+	Role    string
+	Expires time.Time
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("tuf: %s metadata expired at %s", e.Role, e.Expires.Format(time.RFC3339))
+}
+
+// RollbackError is returned when a referenced metadata file's version or
+// hash does not match what a higher role recorded for it, indicating a
+// potential rollback or mix-and-match attack.
+type RollbackError struct {
+	File   string
+	Reason string
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("tuf: %s failed rollback check: %s", e.File, e.Reason)
+}
+
+// FileIntegrityError is returned when a file on disk does not match its
+// entry in targets.json, or has no entry at all.
+type FileIntegrityError struct {
+	Path   string
+	Reason string
+}
+
+func (e *FileIntegrityError) Error() string {
+	return fmt.Sprintf("tuf: %s: %s", e.Path, e.Reason)
+}
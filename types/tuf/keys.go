@@ -0,0 +1,143 @@
+package tuf
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+)
+
+// Signer methods, matching the "method" field of a Signature.
+const (
+	MethodEd25519      = "ed25519"
+	MethodRSAPSSSHA256 = "rsassa-pss-sha256"
+)
+
+// PrivateKey is a key usable to sign a document, together with the public
+// Key record it corresponds to.
+type PrivateKey struct {
+	ID  string
+	Pub Key
+
+	ed25519Priv ed25519.PrivateKey
+	rsaPriv     *rsa.PrivateKey
+}
+
+// NewEd25519Key generates a new ed25519 signing key.
+func NewEd25519Key() (*PrivateKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key := Key{
+		Type:   MethodEd25519,
+		Scheme: MethodEd25519,
+		Value:  KeyValue{Public: hex.EncodeToString(pub)},
+	}
+	return &PrivateKey{ID: keyID(key), Pub: key, ed25519Priv: priv}, nil
+}
+
+// NewRSAKey generates a new RSA-PSS signing key of the given bit size.
+func NewRSAKey(bits int) (*PrivateKey, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key := Key{
+		Type:   "rsa",
+		Scheme: MethodRSAPSSSHA256,
+		Value:  KeyValue{Public: hex.EncodeToString(pubDER)},
+	}
+	return &PrivateKey{ID: keyID(key), Pub: key, rsaPriv: priv}, nil
+}
+
+// keyID derives the stable identifier of a public key as the hex SHA-256
+// of its canonical encoding.
+func keyID(k Key) string {
+	b, err := canonicalJSON(k)
+	if err != nil {
+		// Key always marshals; a failure here indicates a programming error.
+		panic(err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign signs the canonical encoding of signed with key, returning a
+// Signature ready to append to a Signed envelope.
+func sign(key *PrivateKey, canonical []byte) (Signature, error) {
+	switch {
+	case key.ed25519Priv != nil:
+		sig := ed25519.Sign(key.ed25519Priv, canonical)
+		return Signature{KeyID: key.ID, Method: MethodEd25519, Sig: hex.EncodeToString(sig)}, nil
+	case key.rsaPriv != nil:
+		digest := sha256.Sum256(canonical)
+		sig, err := rsa.SignPSS(rand.Reader, key.rsaPriv, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			return Signature{}, err
+		}
+		return Signature{KeyID: key.ID, Method: MethodRSAPSSSHA256, Sig: hex.EncodeToString(sig)}, nil
+	default:
+		return Signature{}, fmt.Errorf("tuf: key %s has no private material", key.ID)
+	}
+}
+
+// verifySignature verifies sig over canonical using the given public key
+// record.
+func verifySignature(pub Key, sig Signature, canonical []byte) error {
+	rawSig, err := hex.DecodeString(sig.Sig)
+	if err != nil {
+		return fmt.Errorf("tuf: malformed signature for key %s: %s", sig.KeyID, err)
+	}
+	switch sig.Method {
+	case MethodEd25519:
+		rawPub, err := hex.DecodeString(pub.Value.Public)
+		if err != nil {
+			return fmt.Errorf("tuf: malformed ed25519 key %s: %s", sig.KeyID, err)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(rawPub), canonical, rawSig) {
+			return fmt.Errorf("tuf: invalid signature for key %s", sig.KeyID)
+		}
+		return nil
+	case MethodRSAPSSSHA256:
+		rawPub, err := hex.DecodeString(pub.Value.Public)
+		if err != nil {
+			return fmt.Errorf("tuf: malformed rsa key %s: %s", sig.KeyID, err)
+		}
+		pubKey, err := x509.ParsePKIXPublicKey(rawPub)
+		if err != nil {
+			return fmt.Errorf("tuf: malformed rsa key %s: %s", sig.KeyID, err)
+		}
+		rsaPub, ok := pubKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("tuf: key %s is not an RSA key", sig.KeyID)
+		}
+		digest := sha256.Sum256(canonical)
+		if err := rsa.VerifyPSS(rsaPub, crypto.SHA256, digest[:], rawSig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return fmt.Errorf("tuf: invalid signature for key %s: %s", sig.KeyID, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("tuf: unsupported signature method %q", sig.Method)
+	}
+}
+
+// hashFile computes the sha256 and sha512 hashes and length of raw, as
+// recorded in TargetFileMeta/MetaFileMeta.
+func hashFile(raw []byte) (map[string]string, int64) {
+	sha256Sum := sha256.Sum256(raw)
+	sha512Sum := sha512.Sum512(raw)
+	return map[string]string{
+		"sha256": hex.EncodeToString(sha256Sum[:]),
+		"sha512": hex.EncodeToString(sha512Sum[:]),
+	}, int64(len(raw))
+}
@@ -0,0 +1,55 @@
+package tuf
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SignDocument canonicalizes payload and signs it with every given key,
+// returning the resulting envelope.
+func SignDocument(payload interface{}, keys ...*PrivateKey) (*Signed, error) {
+	canonical, err := canonicalJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	signed := &Signed{Signed: json.RawMessage(canonical)}
+	for _, key := range keys {
+		sig, err := sign(key, canonical)
+		if err != nil {
+			return nil, err
+		}
+		signed.Signatures = append(signed.Signatures, sig)
+	}
+	return signed, nil
+}
+
+// NewRole builds the RoleKeys record for a set of keys and a threshold.
+func NewRole(threshold int, keys ...*PrivateKey) RoleKeys {
+	role := RoleKeys{Threshold: threshold}
+	for _, k := range keys {
+		role.KeyIDs = append(role.KeyIDs, k.ID)
+	}
+	return role
+}
+
+// NewRoot builds a RootSigned listing the given keys and per-role
+// thresholds. keysByRole maps each of RoleRoot/RoleTargets/RoleSnapshot/
+// RoleTimestamp to the keys trusted to sign it.
+func NewRoot(version int, expires time.Time, thresholds map[string]int, keysByRole map[string][]*PrivateKey) *RootSigned {
+	root := &RootSigned{
+		Type:    RoleRoot,
+		Version: version,
+		Expires: expires,
+		Keys:    map[string]Key{},
+		Roles:   map[string]RoleKeys{},
+	}
+	for role, keys := range keysByRole {
+		r := RoleKeys{Threshold: thresholds[role]}
+		for _, k := range keys {
+			root.Keys[k.ID] = k.Pub
+			r.KeyIDs = append(r.KeyIDs, k.ID)
+		}
+		root.Roles[role] = r
+	}
+	return root
+}
@@ -0,0 +1,322 @@
+package tuf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+type testChain struct {
+	rootKeys      []*PrivateKey
+	targetsKey    *PrivateKey
+	snapshotKey   *PrivateKey
+	timestampKey  *PrivateKey
+	trustedRoot   *RootSigned
+	rootSigned    *Signed
+	targetsSigned *Signed
+}
+
+// walkFilePaths lists every regular file under dir, relative to it, for
+// tests to pass to NewTargets in place of a production caller's precise
+// (e.g. ignore-filtered) file set.
+func walkFilePaths(t *testing.T, dir string) []string {
+	t.Helper()
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	assert.NilError(t, err)
+	return paths
+}
+
+func buildChain(t *testing.T, dir string, expires time.Time) testChain {
+	t.Helper()
+	root1, err := NewEd25519Key()
+	assert.NilError(t, err)
+	root2, err := NewEd25519Key()
+	assert.NilError(t, err)
+	targetsKey, err := NewEd25519Key()
+	assert.NilError(t, err)
+	snapshotKey, err := NewEd25519Key()
+	assert.NilError(t, err)
+	timestampKey, err := NewEd25519Key()
+	assert.NilError(t, err)
+
+	root := NewRoot(1, time.Now().Add(24*time.Hour), map[string]int{
+		RoleRoot: 2, RoleTargets: 1, RoleSnapshot: 1, RoleTimestamp: 1,
+	}, map[string][]*PrivateKey{
+		RoleRoot:      {root1, root2},
+		RoleTargets:   {targetsKey},
+		RoleSnapshot:  {snapshotKey},
+		RoleTimestamp: {timestampKey},
+	})
+	rootSigned, err := SignDocument(root, root1, root2)
+	assert.NilError(t, err)
+
+	targets, err := NewTargets(dir, walkFilePaths(t, dir), 1, expires)
+	assert.NilError(t, err)
+	targetsSigned, err := SignDocument(targets, targetsKey)
+	assert.NilError(t, err)
+
+	targetsHashes, targetsLength := hashFile(targetsSigned.Signed)
+	snapshot := &SnapshotSigned{
+		Type: RoleSnapshot, Version: 1, Expires: expires,
+		Meta: map[string]MetaFileMeta{
+			TargetsFileName: {Version: 1, Length: targetsLength, Hashes: targetsHashes},
+		},
+	}
+	snapshotSigned, err := SignDocument(snapshot, snapshotKey)
+	assert.NilError(t, err)
+
+	snapshotHashes, snapshotLength := hashFile(snapshotSigned.Signed)
+	timestamp := &TimestampSigned{
+		Type: RoleTimestamp, Version: 1, Expires: expires,
+		Meta: map[string]MetaFileMeta{
+			SnapshotFileName: {Version: 1, Length: snapshotLength, Hashes: snapshotHashes},
+		},
+	}
+	timestampSigned, err := SignDocument(timestamp, timestampKey)
+	assert.NilError(t, err)
+
+	sigDir := filepath.Join(dir, SignaturesDir)
+	assert.NilError(t, os.MkdirAll(sigDir, 0o755))
+	writeSigned(t, sigDir, RootFileName, rootSigned)
+	writeSigned(t, sigDir, TargetsFileName, targetsSigned)
+	writeSigned(t, sigDir, SnapshotFileName, snapshotSigned)
+	writeSigned(t, sigDir, TimestampFileName, timestampSigned)
+
+	return testChain{
+		rootKeys:      []*PrivateKey{root1, root2},
+		targetsKey:    targetsKey,
+		snapshotKey:   snapshotKey,
+		timestampKey:  timestampKey,
+		trustedRoot:   root,
+		rootSigned:    rootSigned,
+		targetsSigned: targetsSigned,
+	}
+}
+
+func writeSigned(t *testing.T, dir, name string, signed *Signed) {
+	t.Helper()
+	raw, err := json.Marshal(signed)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, name), raw, 0o644)
+	assert.NilError(t, err)
+}
+
+func TestVerifyChainHappyPath(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-happy",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+		fs.WithFile("docker-compose.yml", "version: \"3.0\""),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(24*time.Hour))
+
+	root, err := VerifyRoot(chain.trustedRoot, chain.rootSigned)
+	assert.NilError(t, err)
+
+	timestampSigned := readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), TimestampFileName)
+	timestamp, err := VerifyTimestamp(root, timestampSigned)
+	assert.NilError(t, err)
+
+	snapshotSigned := readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), SnapshotFileName)
+	snapshot, err := VerifySnapshot(root, timestamp, snapshotSigned)
+	assert.NilError(t, err)
+
+	targetsSigned := readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), TargetsFileName)
+	targets, err := VerifyTargets(root, snapshot, targetsSigned)
+	assert.NilError(t, err)
+
+	assert.NilError(t, VerifyDir(targets, dir.Path()))
+}
+
+func TestVerifyRootKeyRotation(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-rotation",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(24*time.Hour))
+
+	newRootKey, err := NewEd25519Key()
+	assert.NilError(t, err)
+	newRoot := NewRoot(2, time.Now().Add(24*time.Hour), map[string]int{
+		RoleRoot: 1, RoleTargets: 1, RoleSnapshot: 1, RoleTimestamp: 1,
+	}, map[string][]*PrivateKey{
+		RoleRoot:      {newRootKey},
+		RoleTargets:   {chain.targetsKey},
+		RoleSnapshot:  {chain.snapshotKey},
+		RoleTimestamp: {chain.timestampKey},
+	})
+	// Signed by the OLD root keys, per the trustedRoot's threshold, so
+	// VerifyRoot accepts the rotation.
+	newRootSigned, err := SignDocument(newRoot, chain.rootKeys...)
+	assert.NilError(t, err)
+
+	rotated, err := VerifyRoot(chain.trustedRoot, newRootSigned)
+	assert.NilError(t, err)
+	assert.Equal(t, rotated.Version, 2)
+	_, hasOld := rotated.Roles[RoleRoot]
+	assert.Assert(t, hasOld)
+}
+
+func TestVerifyRootThresholdNotMet(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-threshold",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(24*time.Hour))
+
+	// Re-sign the same root document with only one of the two required
+	// root keys.
+	underSigned, err := SignDocument(chain.trustedRoot, chain.rootKeys[0])
+	assert.NilError(t, err)
+
+	_, err = VerifyRoot(chain.trustedRoot, underSigned)
+	assert.Assert(t, err != nil)
+	_, ok := err.(*ThresholdError)
+	assert.Assert(t, ok)
+}
+
+func TestVerifyRootExpired(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-root-expired",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(24*time.Hour))
+
+	expiredRoot := NewRoot(2, time.Now().Add(-time.Hour), map[string]int{
+		RoleRoot: 2, RoleTargets: 1, RoleSnapshot: 1, RoleTimestamp: 1,
+	}, map[string][]*PrivateKey{
+		RoleRoot:      chain.rootKeys,
+		RoleTargets:   {chain.targetsKey},
+		RoleSnapshot:  {chain.snapshotKey},
+		RoleTimestamp: {chain.timestampKey},
+	})
+	expiredRootSigned, err := SignDocument(expiredRoot, chain.rootKeys[0], chain.rootKeys[1])
+	assert.NilError(t, err)
+
+	_, err = VerifyRoot(chain.trustedRoot, expiredRootSigned)
+	assert.Assert(t, err != nil)
+	_, ok := err.(*ExpiredError)
+	assert.Assert(t, ok)
+}
+
+func TestVerifyTimestampExpired(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-expired",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(-time.Hour))
+	root, err := VerifyRoot(chain.trustedRoot, chain.rootSigned)
+	assert.NilError(t, err)
+
+	timestampSigned := readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), TimestampFileName)
+	_, err = VerifyTimestamp(root, timestampSigned)
+	assert.Assert(t, err != nil)
+	_, ok := err.(*ExpiredError)
+	assert.Assert(t, ok)
+}
+
+func TestVerifyDirTamperedAttachment(t *testing.T) {
+	dir := fs.NewDir(t, "tuf-tampered",
+		fs.WithFile("metadata.yml", "name: test\nversion: 0.1.0"),
+		fs.WithFile("dataset.csv", "a,b,c\n1,2,3\n"),
+	)
+	defer dir.Remove()
+
+	chain := buildChain(t, dir.Path(), time.Now().Add(24*time.Hour))
+	root, err := VerifyRoot(chain.trustedRoot, chain.rootSigned)
+	assert.NilError(t, err)
+	timestamp, err := VerifyTimestamp(root, readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), TimestampFileName))
+	assert.NilError(t, err)
+	snapshot, err := VerifySnapshot(root, timestamp, readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), SnapshotFileName))
+	assert.NilError(t, err)
+	targets, err := VerifyTargets(root, snapshot, readSignedFixture(t, filepath.Join(dir.Path(), SignaturesDir), TargetsFileName))
+	assert.NilError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir.Path(), "dataset.csv"), []byte("tampered"), 0o644)
+	assert.NilError(t, err)
+
+	err = VerifyDir(targets, dir.Path())
+	assert.Assert(t, err != nil)
+	integrityErr, ok := err.(*FileIntegrityError)
+	assert.Assert(t, ok)
+	assert.Equal(t, integrityErr.Path, "dataset.csv")
+}
+
+func TestVerifyFileRejectsMetaWithNoHashes(t *testing.T) {
+	raw := []byte("a,b,c\n1,2,3\n")
+	targets := &TargetsSigned{
+		Targets: map[string]TargetFileMeta{
+			"dataset.csv": {Length: int64(len(raw))},
+		},
+	}
+
+	err := VerifyFile(targets, "dataset.csv", raw)
+	assert.Assert(t, err != nil)
+	integrityErr, ok := err.(*FileIntegrityError)
+	assert.Assert(t, ok)
+	assert.Equal(t, integrityErr.Reason, "no hashes recorded in targets.json")
+}
+
+func TestCheckMetaRejectsMetaWithNoHashes(t *testing.T) {
+	raw := json.RawMessage(`{"version":1}`)
+	meta := MetaFileMeta{Version: 1, Length: int64(len(raw))}
+
+	err := checkMeta("targets.json", meta, raw)
+	assert.Assert(t, err != nil)
+	rollbackErr, ok := err.(*RollbackError)
+	assert.Assert(t, ok)
+	assert.Equal(t, rollbackErr.Reason, "no hashes recorded")
+}
+
+func readSignedFixture(t *testing.T, dir, name string) *Signed {
+	t.Helper()
+	raw, err := ioutil.ReadFile(filepath.Join(dir, name))
+	assert.NilError(t, err)
+	var signed Signed
+	assert.NilError(t, json.Unmarshal(raw, &signed))
+	return &signed
+}
+
+func TestCanonicalJSONPreservesLargeIntegers(t *testing.T) {
+	doc := map[string]interface{}{"length": int64(9223372036854775807)}
+	raw, err := canonicalJSON(doc)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), `{"length":9223372036854775807}`)
+}
+
+func TestSignDocumentIsReproducible(t *testing.T) {
+	key, err := NewEd25519Key()
+	assert.NilError(t, err)
+	doc := map[string]interface{}{"b": 1, "a": 2}
+	s1, err := SignDocument(doc, key)
+	assert.NilError(t, err)
+	s2, err := SignDocument(doc, key)
+	assert.NilError(t, err)
+	assert.Assert(t, is.Equal(string(s1.Signed), string(s2.Signed)))
+	assert.Equal(t, s1.Signatures[0].Sig, s2.Signatures[0].Sig)
+}
@@ -0,0 +1,117 @@
+// Package tuf implements a minimal subset of The Update Framework's role
+// model (https://theupdateframework.io): a self-signed root of trust,
+// signed target metadata with content hashes, a snapshot pinning the
+// targets version, and a timestamp bounding freshness. It is used to give
+// App bundles verifiable, tamper-evident metadata and attachments.
+package tuf
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Role names, matching the "_type" field of each signed document.
+const (
+	RoleRoot      = "root"
+	RoleTargets   = "targets"
+	RoleSnapshot  = "snapshot"
+	RoleTimestamp = "timestamp"
+)
+
+// File names of the four signed metadata documents, relative to the
+// signatures directory of an App.
+const (
+	RootFileName      = "root.json"
+	TargetsFileName   = "targets.json"
+	SnapshotFileName  = "snapshot.json"
+	TimestampFileName = "timestamp.json"
+)
+
+// SignaturesDir is the name of the directory, relative to an App's root,
+// holding the signed metadata files.
+const SignaturesDir = "signatures"
+
+// Signature is one signature over a signed document, by the key
+// identified by KeyID.
+type Signature struct {
+	KeyID  string `json:"keyid"`
+	Method string `json:"method"`
+	Sig    string `json:"sig"`
+}
+
+// Signed wraps an arbitrary signed payload together with the signatures
+// made over its canonical encoding.
+type Signed struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []Signature     `json:"signatures"`
+}
+
+// Key is a public key trusted to sign one or more roles.
+type Key struct {
+	Type   string   `json:"keytype"`
+	Scheme string   `json:"scheme"`
+	Value  KeyValue `json:"keyval"`
+}
+
+// KeyValue holds the hex-encoded public key material.
+type KeyValue struct {
+	Public string `json:"public"`
+}
+
+// RoleKeys lists the keys trusted for a role and how many of them must
+// sign for the role to be considered valid.
+type RoleKeys struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// RootSigned is the signed payload of root.json: the set of trusted keys
+// and the threshold required for each role.
+type RootSigned struct {
+	Type    string              `json:"_type"`
+	Version int                 `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Keys    map[string]Key      `json:"keys"`
+	Roles   map[string]RoleKeys `json:"roles"`
+}
+
+// TargetFileMeta describes one file tracked by targets.json.
+type TargetFileMeta struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// TargetsSigned is the signed payload of targets.json: every file the app
+// ships, with its length and hashes.
+type TargetsSigned struct {
+	Type    string                    `json:"_type"`
+	Version int                       `json:"version"`
+	Expires time.Time                 `json:"expires"`
+	Targets map[string]TargetFileMeta `json:"targets"`
+}
+
+// MetaFileMeta describes a referenced metadata file: its version, and
+// optionally its length and hashes.
+type MetaFileMeta struct {
+	Version int               `json:"version"`
+	Length  int64             `json:"length,omitempty"`
+	Hashes  map[string]string `json:"hashes,omitempty"`
+}
+
+// SnapshotSigned is the signed payload of snapshot.json: the version and
+// hash of targets.json, preventing mix-and-match rollback attacks.
+type SnapshotSigned struct {
+	Type    string                  `json:"_type"`
+	Version int                     `json:"version"`
+	Expires time.Time               `json:"expires"`
+	Meta    map[string]MetaFileMeta `json:"meta"`
+}
+
+// TimestampSigned is the signed payload of timestamp.json: the version
+// and hash of snapshot.json, plus an expiration bounding freshness.
+type TimestampSigned struct {
+	Type    string                  `json:"_type"`
+	Version int                     `json:"version"`
+	Expires time.Time               `json:"expires"`
+	Meta    map[string]MetaFileMeta `json:"meta"`
+}
@@ -0,0 +1,166 @@
+package tuf
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// verifyThreshold counts how many of signed's signatures are valid,
+// trusted for role (i.e. their keyid is in role.KeyIDs and resolves in
+// keys), and returns an error if fewer than role.Threshold are valid.
+func verifyThreshold(roleName string, role RoleKeys, keys map[string]Key, signed *Signed) error {
+	trusted := map[string]bool{}
+	for _, id := range role.KeyIDs {
+		trusted[id] = true
+	}
+	valid := 0
+	seen := map[string]bool{}
+	for _, sig := range signed.Signatures {
+		if seen[sig.KeyID] || !trusted[sig.KeyID] {
+			continue
+		}
+		pub, ok := keys[sig.KeyID]
+		if !ok {
+			continue
+		}
+		if err := verifySignature(pub, sig, signed.Signed); err == nil {
+			valid++
+			seen[sig.KeyID] = true
+		}
+	}
+	if valid < role.Threshold {
+		return &ThresholdError{Role: roleName, Valid: valid, Threshold: role.Threshold}
+	}
+	return nil
+}
+
+// VerifyRoot verifies candidate against the keys and threshold of
+// trustedRoot's root role (supporting key rotation: trustedRoot is
+// whatever root of trust the caller already has, typically the previous
+// root.json) and returns the new, now-trusted RootSigned.
+func VerifyRoot(trustedRoot *RootSigned, candidate *Signed) (*RootSigned, error) {
+	role := trustedRoot.Roles[RoleRoot]
+	if err := verifyThreshold(RoleRoot, role, trustedRoot.Keys, candidate); err != nil {
+		return nil, err
+	}
+	var root RootSigned
+	if err := json.Unmarshal(candidate.Signed, &root); err != nil {
+		return nil, err
+	}
+	if time.Now().After(root.Expires) {
+		return nil, &ExpiredError{Role: RoleRoot, Expires: root.Expires}
+	}
+	return &root, nil
+}
+
+// VerifyTimestamp verifies signed against root's timestamp role and
+// checks it has not expired.
+func VerifyTimestamp(root *RootSigned, signed *Signed) (*TimestampSigned, error) {
+	if err := verifyThreshold(RoleTimestamp, root.Roles[RoleTimestamp], root.Keys, signed); err != nil {
+		return nil, err
+	}
+	var ts TimestampSigned
+	if err := json.Unmarshal(signed.Signed, &ts); err != nil {
+		return nil, err
+	}
+	if time.Now().After(ts.Expires) {
+		return nil, &ExpiredError{Role: RoleTimestamp, Expires: ts.Expires}
+	}
+	return &ts, nil
+}
+
+// VerifySnapshot verifies signed against root's snapshot role, checks it
+// has not expired, and checks it matches what timestamp recorded for it.
+func VerifySnapshot(root *RootSigned, timestamp *TimestampSigned, signed *Signed) (*SnapshotSigned, error) {
+	if err := verifyThreshold(RoleSnapshot, root.Roles[RoleSnapshot], root.Keys, signed); err != nil {
+		return nil, err
+	}
+	meta, ok := timestamp.Meta[SnapshotFileName]
+	if !ok {
+		return nil, &RollbackError{File: SnapshotFileName, Reason: "not referenced by timestamp.json"}
+	}
+	if err := checkMeta(SnapshotFileName, meta, signed.Signed); err != nil {
+		return nil, err
+	}
+	var snapshot SnapshotSigned
+	if err := json.Unmarshal(signed.Signed, &snapshot); err != nil {
+		return nil, err
+	}
+	if time.Now().After(snapshot.Expires) {
+		return nil, &ExpiredError{Role: RoleSnapshot, Expires: snapshot.Expires}
+	}
+	return &snapshot, nil
+}
+
+// VerifyTargets verifies signed against root's targets role and checks it
+// matches what snapshot recorded for it.
+func VerifyTargets(root *RootSigned, snapshot *SnapshotSigned, signed *Signed) (*TargetsSigned, error) {
+	if err := verifyThreshold(RoleTargets, root.Roles[RoleTargets], root.Keys, signed); err != nil {
+		return nil, err
+	}
+	meta, ok := snapshot.Meta[TargetsFileName]
+	if !ok {
+		return nil, &RollbackError{File: TargetsFileName, Reason: "not referenced by snapshot.json"}
+	}
+	if err := checkMeta(TargetsFileName, meta, signed.Signed); err != nil {
+		return nil, err
+	}
+	var targets TargetsSigned
+	if err := json.Unmarshal(signed.Signed, &targets); err != nil {
+		return nil, err
+	}
+	if time.Now().After(targets.Expires) {
+		return nil, &ExpiredError{Role: RoleTargets, Expires: targets.Expires}
+	}
+	return &targets, nil
+}
+
+// checkMeta verifies that raw's version and hash match what was recorded
+// about it by a higher-level role.
+func checkMeta(file string, meta MetaFileMeta, raw json.RawMessage) error {
+	var versioned struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &versioned); err != nil {
+		return err
+	}
+	if versioned.Version != meta.Version {
+		return &RollbackError{File: file, Reason: "version does not match"}
+	}
+	if len(meta.Hashes) == 0 {
+		return &RollbackError{File: file, Reason: "no hashes recorded"}
+	}
+	hashes, length := hashFile(raw)
+	if length != meta.Length {
+		return &RollbackError{File: file, Reason: "length does not match"}
+	}
+	for alg, want := range meta.Hashes {
+		if hashes[alg] != want {
+			return &RollbackError{File: file, Reason: "hash does not match"}
+		}
+	}
+	return nil
+}
+
+// VerifyFile checks that raw matches its recorded entry in targets, by
+// path. A path missing from targets, or present with a different size or
+// hash, is rejected.
+func VerifyFile(targets *TargetsSigned, path string, raw []byte) error {
+	meta, ok := targets.Targets[path]
+	if !ok {
+		return &FileIntegrityError{Path: path, Reason: "not listed in targets.json"}
+	}
+	if int64(len(raw)) != meta.Length {
+		return &FileIntegrityError{Path: path, Reason: "size does not match targets.json"}
+	}
+	if len(meta.Hashes) == 0 {
+		return &FileIntegrityError{Path: path, Reason: "no hashes recorded in targets.json"}
+	}
+	hashes, _ := hashFile(raw)
+	for alg, want := range meta.Hashes {
+		if hashes[alg] != want {
+			return &FileIntegrityError{Path: path, Reason: "hash does not match targets.json"}
+		}
+	}
+	return nil
+}
@@ -9,14 +9,16 @@ import (
 	"sort"
 
 	"github.com/docker/app/internal"
+	"github.com/docker/app/types/tuf"
 )
 
 // Attachment is a non-core file shipped alongside an application package
 // (for example a TLS certificate, a dataset, or any other resource the
 // compose services rely on at runtime).
 type Attachment struct {
-	path string
-	size int64
+	path    string
+	absPath string
+	size    int64
 }
 
 // Path returns the attachment path, relative to the application directory.
@@ -29,6 +31,13 @@ func (a Attachment) Size() int64 {
 	return a.size
 }
 
+// Open returns a reader for the attachment content. The caller is
+// responsible for closing it. Content is read from disk on demand rather
+// than held in memory.
+func (a Attachment) Open() (io.ReadCloser, error) {
+	return os.Open(a.absPath)
+}
+
 // App represents an application package: its compose file(s), metadata,
 // parameters, and any attachments shipped alongside them.
 type App struct {
@@ -39,6 +48,13 @@ type App struct {
 	composes    [][]byte
 	parameters  [][]byte
 	attachments []Attachment
+
+	extraIgnorePatterns []string
+
+	maxAttachmentFileSize  int64
+	maxAttachmentTotalSize int64
+
+	trustedRoot *tuf.RootSigned
 }
 
 // Option is a functional option used to build an App.
@@ -201,7 +217,11 @@ func NewAppFromDefaultFiles(dir string, ops ...Option) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	attachments, err := collectAttachments(dir)
+	matcher, err := newIgnoreMatcher(dir, app.extraIgnorePatterns)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := collectAttachments(dir, matcher, app.maxAttachmentFileSize, app.maxAttachmentTotalSize)
 	if err != nil {
 		return nil, err
 	}
@@ -209,34 +229,63 @@ func NewAppFromDefaultFiles(dir string, ops ...Option) (*App, error) {
 	return app, nil
 }
 
-func collectAttachments(dir string) ([]Attachment, error) {
+// collectAttachments walks dir, statting (but not reading) every non-core,
+// non-ignored file to build the attachment list. Actual content is only
+// read later, on demand, via Attachment.Open.
+func collectAttachments(dir string, matcher *ignoreMatcher, maxFileSize, maxTotalSize int64) ([]Attachment, error) {
 	coreFiles := map[string]bool{
 		internal.MetadataFileName:   true,
 		internal.ComposeFileName:    true,
 		internal.ParametersFileName: true,
+		appIgnoreFileName:           true,
+		dockerIgnoreFileName:        true,
 	}
 	var attachments []Attachment
+	var totalSize int64
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return relErr
+		}
+		if rel == "." {
 			return nil
 		}
-		rel, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if matcher.match(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 		if filepath.Dir(rel) == "." && coreFiles[filepath.Base(rel)] {
 			return nil
 		}
+		if matcher.match(rel, false) {
+			return nil
+		}
+		size := info.Size()
+		if maxFileSize > 0 && size > maxFileSize {
+			return &AttachmentSizeError{Path: rel, Size: size, Limit: maxFileSize}
+		}
+		totalSize += size
+		if maxTotalSize > 0 && totalSize > maxTotalSize {
+			return &AttachmentSizeError{Path: rel, Size: totalSize, Limit: maxTotalSize, Total: true}
+		}
 		attachments = append(attachments, Attachment{
-			path: filepath.ToSlash(rel),
-			size: info.Size(),
+			path:    rel,
+			absPath: path,
+			size:    size,
 		})
 		return nil
 	})
 	if err != nil {
+		if sizeErr, ok := err.(*AttachmentSizeError); ok {
+			return nil, sizeErr
+		}
 		return nil, fmt.Errorf("failed to collect attachments: %s", err)
 	}
 	sort.Slice(attachments, func(i, j int) bool {
@@ -3,9 +3,12 @@ package types
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
+	"github.com/docker/app/internal"
 	"github.com/xeipuuv/gojsonschema"
 	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 // metadataSchemaLoader is the compiled JSON schema used to validate metadata.yml.
@@ -22,47 +25,111 @@ func validateMetadata(raw []byte) error {
 	if err != nil {
 		return fmt.Errorf("failed to validate metadata: %s", err)
 	}
-	if !result.Valid() {
-		var buf bytes.Buffer
-		buf.WriteString("failed to validate metadata:")
-		for _, e := range result.Errors() {
-			buf.WriteString(fmt.Sprintf("\n- %s", e))
+	if result.Valid() {
+		return nil
+	}
+
+	root := positionRoot(raw)
+	var buf bytes.Buffer
+	buf.WriteString("failed to validate metadata:")
+	issues := make([]ValidationIssue, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		buf.WriteString(fmt.Sprintf("\n- %s", e))
+		issues = append(issues, metadataIssue(root, e))
+	}
+	return &ValidationError{message: buf.String(), issues: issues}
+}
+
+// metadataIssue converts a gojsonschema result error into a
+// ValidationIssue, resolving its position against root.
+func metadataIssue(root *yamlv3.Node, e gojsonschema.ResultError) ValidationIssue {
+	var path []string
+	if field := e.Field(); field != "" && field != "(root)" {
+		path = strings.Split(field, ".")
+	}
+	// A required-property error's Field() is its parent object, not the
+	// (absent) property itself; the property name is in Details instead.
+	if e.Type() == "required" {
+		if property, ok := e.Details()["property"].(string); ok {
+			path = append(path, property)
 		}
-		return fmt.Errorf(buf.String())
 	}
-	return nil
+
+	pointer := strings.Join(path, ".")
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	codeParts := append([]string{"metadata"}, path...)
+	codeParts = append(codeParts, e.Type())
+
+	line, column := resolvePosition(root, path)
+	return ValidationIssue{
+		File:     internal.MetadataFileName,
+		Pointer:  pointer,
+		Line:     line,
+		Column:   column,
+		Severity: SeverityError,
+		Code:     strings.Join(codeParts, "."),
+		Message:  e.Description(),
+	}
 }
 
 // validateParameters rejects parameter files using non-string keys, which
 // YAML permits but JSON (and our tooling) does not.
 func validateParameters(raw []byte) error {
-	var data interface{}
-	if err := yaml.Unmarshal(raw, &data); err != nil {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(raw, &doc); err != nil {
 		return fmt.Errorf("failed to parse parameters: %s", err)
 	}
-	return checkStringKeys("", data)
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	path, key := checkStringKeys(nil, doc.Content[0])
+	if key == nil {
+		return nil
+	}
+
+	pointer := strings.Join(path, ".")
+	message := fmt.Sprintf("Non-string key in %s: %s", pointer, key.Value)
+	return &ValidationError{
+		message: message,
+		issues: []ValidationIssue{{
+			File:     internal.ParametersFileName,
+			Pointer:  pointer,
+			Line:     key.Line,
+			Column:   key.Column,
+			Severity: SeverityError,
+			Code:     "parameters.non_string_key",
+			Message:  message,
+		}},
+	}
 }
 
-func checkStringKeys(path string, node interface{}) error {
-	switch n := node.(type) {
-	case map[interface{}]interface{}:
-		for k, v := range n {
-			key, ok := k.(string)
-			if !ok {
-				return fmt.Errorf("Non-string key in %s: %v", path, k)
+// checkStringKeys recursively looks for a mapping key that isn't a plain
+// YAML string (e.g. an integer), returning its path and node, or a nil
+// node if every key is a string.
+func checkStringKeys(path []string, node *yamlv3.Node) ([]string, *yamlv3.Node) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag != "!!str" {
+				return path, key
 			}
-			if err := checkStringKeys(key, v); err != nil {
-				return err
+			childPath := append(append([]string{}, path...), key.Value)
+			if p, k := checkStringKeys(childPath, value); k != nil {
+				return p, k
 			}
 		}
-	case []interface{}:
-		for _, v := range n {
-			if err := checkStringKeys(path, v); err != nil {
-				return err
+	case yamlv3.SequenceNode:
+		for _, item := range node.Content {
+			if p, k := checkStringKeys(path, item); k != nil {
+				return p, k
 			}
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 // convertYAMLMapKeys converts the map[interface{}]interface{} produced by
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"strconv"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// positionRoot parses raw with yaml.v3, which (unlike yaml.v2) preserves
+// source positions, so ValidationIssue Line/Column can be resolved against
+// it. Parse failures here are non-fatal: the caller already parsed raw
+// successfully with yaml.v2 to get this far, so issues are still reported,
+// just without a resolved position.
+func positionRoot(raw []byte) *yamlv3.Node {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(raw, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// resolvePosition walks path (map keys and array indices) from root,
+// returning the line/column of the deepest node it can reach. If the full
+// path doesn't resolve (for example a required property that is missing
+// has no node of its own), it falls back to the last node it did reach.
+func resolvePosition(root *yamlv3.Node, path []string) (line, column int) {
+	node := root
+	if node == nil {
+		return 0, 0
+	}
+	line, column = node.Line, node.Column
+	for _, segment := range path {
+		next := stepPosition(node, segment)
+		if next == nil {
+			break
+		}
+		node = next
+		line, column = node.Line, node.Column
+	}
+	return line, column
+}
+
+// stepPosition returns the child of node named or indexed by segment, or
+// nil if node has no such child.
+func stepPosition(node *yamlv3.Node, segment string) *yamlv3.Node {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1]
+			}
+		}
+	case yamlv3.SequenceNode:
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+			return node.Content[index]
+		}
+	}
+	return nil
+}
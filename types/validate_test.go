@@ -0,0 +1,94 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/docker/app/internal"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+const brokenMetadata = `#version: 0.1.0-missing
+name: MustBeAValidUntaggedRegistryReferenceButNotEvaluatedByTheSchema
+maintainers:
+    - name: user
+      email: user@email.com
+    - name: user2
+    - name: bad-user
+      email: bad-email
+unknown: property`
+
+func TestMetadataValidationErrorIssues(t *testing.T) {
+	app := &App{Path: "my-app"}
+	err := Metadata(strings.NewReader(brokenMetadata))(app)
+	assert.Assert(t, err != nil)
+
+	validationErr, ok := err.(*ValidationError)
+	assert.Assert(t, ok)
+	assert.Assert(t, is.Len(validationErr.Issues(), 2))
+
+	required := validationErr.Issues()[0]
+	assert.Equal(t, required.File, internal.MetadataFileName)
+	assert.Equal(t, required.Pointer, "version")
+	assert.Equal(t, required.Code, "metadata.version.required")
+	assert.Equal(t, required.Severity, SeverityError)
+	assert.Equal(t, required.Line, 2)
+	assert.Equal(t, required.Column, 1)
+
+	format := validationErr.Issues()[1]
+	assert.Equal(t, format.File, internal.MetadataFileName)
+	assert.Equal(t, format.Pointer, "maintainers.2.email")
+	assert.Equal(t, format.Code, "metadata.maintainers.2.email.format")
+	assert.Equal(t, format.Severity, SeverityError)
+	assert.Equal(t, format.Line, 8)
+	assert.Equal(t, format.Column, 14)
+}
+
+func TestMetadataValidationErrorMarshalJSON(t *testing.T) {
+	app := &App{Path: "my-app"}
+	err := Metadata(strings.NewReader(brokenMetadata))(app)
+	validationErr, ok := err.(*ValidationError)
+	assert.Assert(t, ok)
+
+	raw, marshalErr := json.Marshal(validationErr)
+	assert.NilError(t, marshalErr)
+
+	var issues []ValidationIssue
+	assert.NilError(t, json.Unmarshal(raw, &issues))
+	assert.Assert(t, is.Len(issues, 2))
+	assert.Equal(t, issues[0].Code, "metadata.version.required")
+}
+
+func TestMetadataValidationErrorStringUnchanged(t *testing.T) {
+	app := &App{Path: "my-app"}
+	err := Metadata(strings.NewReader(brokenMetadata))(app)
+	assert.Error(t, err, `failed to validate metadata:
+- (root): version is required
+- maintainers.2.email: Does not match format 'email'`)
+}
+
+func TestParametersValidationErrorIssues(t *testing.T) {
+	app := &App{Path: "my-app"}
+	err := Metadata(strings.NewReader(`version: "0.1"
+name: myname`))(app)
+	assert.NilError(t, err)
+	err = WithComposes(strings.NewReader(`version: "3.6"`))(app)
+	assert.NilError(t, err)
+
+	err = WithParameters(strings.NewReader("my-parameters:\n    1: toto"))(app)
+	validationErr, ok := err.(*ValidationError)
+	assert.Assert(t, ok)
+	assert.Assert(t, is.Len(validationErr.Issues(), 1))
+
+	issue := validationErr.Issues()[0]
+	assert.Equal(t, issue.File, internal.ParametersFileName)
+	assert.Equal(t, issue.Pointer, "my-parameters")
+	assert.Equal(t, issue.Code, "parameters.non_string_key")
+	assert.Equal(t, issue.Severity, SeverityError)
+	assert.Equal(t, issue.Line, 2)
+	assert.Equal(t, issue.Column, 5)
+	assert.ErrorContains(t, validationErr, "Non-string key in my-parameters: 1")
+}
@@ -0,0 +1,61 @@
+package types
+
+import "encoding/json"
+
+// Validation severities used by ValidationIssue.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue is a single structured validation failure: a metadata
+// schema violation or a broken parameters key. Unlike ValidationError's
+// Error() string, it's meant to be consumed by tooling (IDE plugins, CI
+// annotators, LSP-style integrations) without parsing a formatted message.
+type ValidationIssue struct {
+	// File is the core file the issue was found in, e.g.
+	// internal.MetadataFileName or internal.ParametersFileName.
+	File string `json:"file"`
+	// Pointer is the dotted YAML path to the offending value, e.g.
+	// "maintainers.2.email", or "(root)" for a document-level issue.
+	Pointer string `json:"pointer"`
+	// Line and Column are the 1-based source position Pointer resolves
+	// to, when it could be resolved. Both are 0 if it couldn't be (for
+	// example a required property that is simply absent has no position
+	// of its own; the enclosing object's position is used instead).
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+	// Severity is SeverityError or SeverityWarning.
+	Severity string `json:"severity"`
+	// Code is a stable, machine-matchable identifier for this kind of
+	// issue, e.g. "metadata.version.required".
+	Code string `json:"code"`
+	// Message is the human-readable description of the issue.
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by Metadata and WithParameters when their
+// content fails validation. Error() keeps the historical
+// "failed to validate ...:\n- ..." formatted string for backward
+// compatibility; callers that want structured access should use Issues()
+// or MarshalJSON instead.
+type ValidationError struct {
+	message string
+	issues  []ValidationIssue
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+// Issues returns the structured validation issues, one per schema or
+// parsing failure.
+func (e *ValidationError) Issues() []ValidationIssue {
+	return e.issues
+}
+
+// MarshalJSON emits Issues() as a JSON array for machine consumption.
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.issues)
+}